@@ -0,0 +1,241 @@
+package classifier
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"html"
+	htmltemplate "html/template"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Directory inside the output directory holding the deck's audio files
+const ankiMediaDir = "anki_media"
+
+// Audio file name used in the deck's [sound:...] reference for a word
+func ankiAudioFileName(word string, audioURL string) string {
+	ext := path.Ext(audioURL)
+	if ext == "" || len(ext) > 5 {
+		ext = ".mp3"
+	}
+	return strings.ReplaceAll(strings.ToLower(word), " ", "_") + ext
+}
+
+// Download a pronunciation file unless it was already downloaded
+func downloadAudio(ctx context.Context, audioURL string, destPath string) error {
+	if _, err := os.Stat(destPath); err == nil {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", audioURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(destPath, data, 0644)
+}
+
+// Card front: the word and, if enabled, its phonetic transcription
+func ankiFront(word string, cachedData WordCache) string {
+	front := html.EscapeString(capitalizePhrase(word))
+	if config.IncludePhonetic && cachedData.Phonetic != "" {
+		front += "<br>" + html.EscapeString(cachedData.Phonetic)
+	}
+	return front
+}
+
+// Card back: definitions with examples, synonyms and antonyms as HTML
+func ankiBack(cachedData WordCache) string {
+	var lines []string
+	shown := 0
+	for _, def := range cachedData.Definitions {
+		if config.FilterNoExample && exampleOf(def) == "" {
+			continue
+		}
+		if definitionLimitReached(shown) {
+			break
+		}
+		shown++
+		lines = append(lines, fmt.Sprintf("<b>%s</b> %s",
+			html.EscapeString(def.PartOfSpeech), html.EscapeString(def.Definition)))
+		if example := exampleOf(def); example != "" {
+			lines = append(lines, "<i>"+html.EscapeString(example)+"</i>")
+		}
+		if config.IncludeSynonyms && len(def.Synonyms) > 0 {
+			lines = append(lines, "Synonyms: "+html.EscapeString(strings.Join(def.Synonyms, ", ")))
+		}
+		if config.IncludeAntonyms && len(def.Antonyms) > 0 {
+			lines = append(lines, "Antonyms: "+html.EscapeString(strings.Join(def.Antonyms, ", ")))
+		}
+	}
+	return strings.Join(lines, "<br>")
+}
+
+// Compiled card templates from AnkiFrontTemplate and AnkiBackTemplate; nil means the built-in layout
+var ankiFrontTemplate, ankiBackTemplate *htmltemplate.Template
+
+// Parse the configured card templates so mistakes are reported at startup. They
+// receive the same data as explanation templates, HTML-escaped automatically.
+func compileAnkiTemplates() error {
+	ankiFrontTemplate, ankiBackTemplate = nil, nil
+	if config.AnkiFrontTemplate != "" {
+		tmpl, err := htmltemplate.New("front").Funcs(htmltemplate.FuncMap(templateFuncs)).Parse(config.AnkiFrontTemplate)
+		if err != nil {
+			return fmt.Errorf("invalid ankiFrontTemplate: %v", err)
+		}
+		ankiFrontTemplate = tmpl
+	}
+	if config.AnkiBackTemplate != "" {
+		tmpl, err := htmltemplate.New("back").Funcs(htmltemplate.FuncMap(templateFuncs)).Parse(config.AnkiBackTemplate)
+		if err != nil {
+			return fmt.Errorf("invalid ankiBackTemplate: %v", err)
+		}
+		ankiBackTemplate = tmpl
+	}
+	return nil
+}
+
+// Render a card side with its template, or the built-in layout if none is configured
+func ankiCardSide(tmpl *htmltemplate.Template, data ExplanationData, builtIn string) string {
+	if tmpl == nil {
+		return builtIn
+	}
+	var output strings.Builder
+	if err := tmpl.Execute(&output, data); err != nil {
+		log.Printf("Error rendering Anki card for %s: %v\n", data.Word, err)
+		return builtIn
+	}
+	return output.String()
+}
+
+// Write Anki.txt, a tab-separated deck Anki imports directly, tagging each card with
+// its category. Returns the number of cards written.
+func writeAnkiDeck(ctx context.Context, path string, wordsByCategory map[string][]string) (int, error) {
+	cards, _, err := writeAnkiCards(ctx, path, wordsByCategory, "")
+	return cards, err
+}
+
+// Write AnkiDeck.txt, the Anki.txt deck plus an audio column that plays downloaded
+// pronunciations. Returns the number of cards written.
+func writeAnkiAudioDeck(ctx context.Context, outputDir string, wordsByCategory map[string][]string) (int, error) {
+	mediaDir := filepath.Join(outputDir, ankiMediaDir)
+	if err := os.MkdirAll(mediaDir, os.ModePerm); err != nil {
+		return 0, err
+	}
+
+	cards, mediaFiles, err := writeAnkiCards(ctx, filepath.Join(outputDir, "AnkiDeck.txt"), wordsByCategory, mediaDir)
+	if err != nil {
+		return cards, err
+	}
+	return cards, writeAnkiMediaManifest(mediaDir, mediaFiles)
+}
+
+// Write a deck with a card for each word of each category, its sides rendered with
+// the Anki templates and tagged with the category. With a media directory the
+// pronunciations are downloaded into it for an Audio column. Returns the number of
+// cards written and the downloaded audio files.
+func writeAnkiCards(ctx context.Context, path string, wordsByCategory map[string][]string, mediaDir string) (int, []string, error) {
+	file, err := createOutputFile(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	writer.WriteString("#separator:tab\n")
+	writer.WriteString("#html:true\n")
+	if mediaDir != "" {
+		writer.WriteString("#columns:Front\tBack\tTags\tAudio\n")
+	} else {
+		writer.WriteString("#columns:Front\tBack\tTags\n")
+	}
+	writer.WriteString("#tags column:3\n")
+
+	total := 0
+	for _, category := range categoryNames {
+		total += len(wordsByCategory[category])
+	}
+
+	// Words listed in several categories share one download
+	sounds := make(map[string]string)
+	var mediaFiles []string
+	cards, done := 0, 0
+	for _, category := range categoryNames {
+		for _, word := range wordsByCategory[category] {
+			if ctx.Err() != nil {
+				return cards, mediaFiles, writer.Flush()
+			}
+			done++
+			if mediaDir != "" {
+				reportProgress("Building Anki deck", word, done, total)
+			}
+
+			cachedData, exists := wordCache[lowerWord(word)]
+			if !exists || len(cachedData.Definitions) == 0 {
+				continue
+			}
+
+			data := explanationData(word, category, cachedData)
+			front := ankiCardSide(ankiFrontTemplate, data, ankiFront(word, cachedData))
+			back := ankiCardSide(ankiBackTemplate, data, ankiBack(cachedData))
+			writer.WriteString(ankiField(front) + "\t" + ankiField(back) + "\t" + category)
+
+			if mediaDir != "" {
+				sound, downloaded := sounds[lowerWord(word)]
+				if !downloaded && cachedData.AudioURL != "" {
+					fileName := ankiAudioFileName(word, cachedData.AudioURL)
+					if err := downloadAudio(ctx, cachedData.AudioURL, filepath.Join(mediaDir, fileName)); err != nil {
+						log.Printf("Error downloading audio for %s: %v\n", word, err)
+					} else {
+						sound = "[sound:" + fileName + "]"
+						mediaFiles = append(mediaFiles, fileName)
+					}
+					sounds[lowerWord(word)] = sound
+				}
+				writer.WriteString("\t" + sound)
+			}
+			writer.WriteString("\n")
+			cards++
+		}
+	}
+	return cards, mediaFiles, writer.Flush()
+}
+
+// Keep a field on a single line of the tab-separated deck
+func ankiField(text string) string {
+	return strings.NewReplacer("\t", " ", "\n", "<br>", "\r", "").Replace(text)
+}
+
+// List the deck's audio files and explain where Anki expects them
+func writeAnkiMediaManifest(mediaDir string, mediaFiles []string) error {
+	var content strings.Builder
+	content.WriteString("Anki media for AnkiDeck.txt\n")
+	content.WriteString("1. Copy every audio file in this folder into your Anki profile's collection.media folder\n")
+	content.WriteString("   (Anki: Tools > Check Media > View Files shows its location).\n")
+	content.WriteString("2. Import AnkiDeck.txt with File > Import; the Audio column plays these files.\n\n")
+	content.WriteString(fmt.Sprintf("Audio files (%d):\n", len(mediaFiles)))
+	for _, fileName := range mediaFiles {
+		content.WriteString(fileName + "\n")
+	}
+	return writeOutputFile(filepath.Join(mediaDir, "README.txt"), []byte(content.String()))
+}
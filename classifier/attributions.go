@@ -0,0 +1,78 @@
+package classifier
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// "... the sentence. — Jane Austen" or "... the sentence - The Times, 1999"
+var dashAttributionPattern = regexp.MustCompile(`\s+(?:—|–|--|-)\s*([^—–]+?)\s*$`)
+
+// "... the sentence (The Guardian, 2004)"
+var parenAttributionPattern = regexp.MustCompile(`\s*\(([^()]+)\)\s*$`)
+
+var yearPattern = regexp.MustCompile(`\b(1[5-9]|20)\d\d\b`)
+
+// Month names are capitalized but date an aside ("(April 1999)") rather than name a source
+var monthNames = map[string]bool{
+	"January": true, "February": true, "March": true, "April": true, "May": true, "June": true, "July": true,
+	"August": true, "September": true, "October": true, "November": true, "December": true,
+}
+
+// Whether the text reads like a source name: short, with every word capitalized,
+// a year, or a connecting word ("The Wall Street Journal", "Dickens, 1859"), and
+// at least minCapitalized capitalized words (one fewer when a year is given)
+func looksLikeSource(text string, minCapitalized int) bool {
+	words := strings.Fields(strings.NewReplacer(",", " ", ".", " ").Replace(text))
+	if len(words) == 0 || len(words) > 8 {
+		return false
+	}
+	connectors := map[string]bool{"of": true, "the": true, "and": true, "in": true, "on": true, "&": true}
+	capitalized := 0
+	for _, word := range words {
+		first := []rune(word)[0]
+		switch {
+		case monthNames[word]:
+		case unicode.IsUpper(first):
+			capitalized++
+		case yearPattern.MatchString(word), connectors[word]:
+		default:
+			return false
+		}
+	}
+	if yearPattern.MatchString(text) {
+		minCapitalized--
+	}
+	return capitalized > 0 && capitalized >= minCapitalized
+}
+
+// Remove a trailing citation from an example sentence, keeping it unchanged unless
+// the tail clearly looks like a source and enough of the sentence remains
+func stripExampleAttribution(example string) string {
+	// Parentheses often hold legitimate asides such as "(New York City)", so they
+	// need stronger evidence than a dash: a year or a comma as in "(Dickens, 1859)"
+	patterns := []struct {
+		pattern        *regexp.Regexp
+		minCapitalized int
+		needsCitation  bool
+	}{
+		{dashAttributionPattern, 1, false},
+		{parenAttributionPattern, 2, true},
+	}
+	for _, p := range patterns {
+		loc := p.pattern.FindStringSubmatchIndex(example)
+		if loc == nil {
+			continue
+		}
+		tail := example[loc[2]:loc[3]]
+		rest := strings.TrimSpace(example[:loc[0]])
+		if p.needsCitation && !strings.Contains(tail, ",") && !yearPattern.MatchString(tail) {
+			continue
+		}
+		if looksLikeSource(tail, p.minCapitalized) && len(strings.Fields(rest)) >= 3 {
+			return rest
+		}
+	}
+	return example
+}
@@ -0,0 +1,41 @@
+package classifier
+
+import "testing"
+
+func TestStripExampleAttribution(t *testing.T) {
+	tests := []struct {
+		example string
+		want    string
+	}{
+		// Trailing sources are removed
+		{"It is a truth universally acknowledged. — Jane Austen", "It is a truth universally acknowledged."},
+		{"The market fell sharply today - The Times, 1999", "The market fell sharply today"},
+		{"The results were in at last -- Wall Street Journal", "The results were in at last"},
+		{"The fox ran into the woods (The Guardian, 2004)", "The fox ran into the woods"},
+		{"She paid the bill at once (Dickens, 1859)", "She paid the bill at once"},
+
+		// Legitimate parentheticals are kept
+		{"He went to the shop (the one on the corner)", "He went to the shop (the one on the corner)"},
+		{"He went to the shop (the one on the corner).", "He went to the shop (the one on the corner)."},
+		{"She finally met her friend (Mary)", "She finally met her friend (Mary)"},
+		{"They moved to a big city (New York City)", "They moved to a big city (New York City)"},
+		{"It happened in the spring (April 1999)", "It happened in the spring (April 1999)"},
+		{"Meet me at five tomorrow (UTC)", "Meet me at five tomorrow (UTC)"},
+
+		// Dashes within the sentence are kept
+		{"The results were clear - everyone agreed", "The results were clear - everyone agreed"},
+		{"I saw him — John — at the park", "I saw him — John — at the park"},
+		{"It was a well-known fact", "It was a well-known fact"},
+		{"The final score was 3 - 1", "The final score was 3 - 1"},
+		{"We arrived late - March 2003", "We arrived late - March 2003"},
+
+		// Too little would remain of the sentence
+		{"Call me - OK", "Call me - OK"},
+		{"Hello (The Times, 1999)", "Hello (The Times, 1999)"},
+	}
+	for _, test := range tests {
+		if got := stripExampleAttribution(test.example); got != test.want {
+			t.Errorf("stripExampleAttribution(%q) = %q, want %q", test.example, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package classifier
+
+import (
+	"bufio"
+	"path"
+	"strings"
+)
+
+// Accents in order of preference when a word has several pronunciation recordings
+var audioAccentPreference = []string{"us", "uk"}
+
+// Accent of a dictionaryapi.dev audio URL from its file name suffix, e.g. "us" for
+// ".../hello-us.mp3". Returns "" when the file name carries no accent.
+func audioAccent(url string) string {
+	name := strings.TrimSuffix(path.Base(url), path.Ext(url))
+	if i := strings.LastIndex(name, "-"); i >= 0 {
+		return strings.ToLower(name[i+1:])
+	}
+	return ""
+}
+
+// Pick the recording to keep: a US one, then a UK one, then the first non-empty URL
+func preferredAudioURL(urls []string) string {
+	for _, accent := range audioAccentPreference {
+		for _, url := range urls {
+			if url != "" && audioAccent(url) == accent {
+				return url
+			}
+		}
+	}
+	for _, url := range urls {
+		if url != "" {
+			return url
+		}
+	}
+	return ""
+}
+
+// Write a word-to-audio-URL list for the words that have a recording. Returns the
+// number of words written.
+func writeAudioList(path string, words []string) (int, error) {
+	file, err := createOutputFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	count := 0
+	for _, word := range words {
+		cachedData, exists := wordCache[lowerWord(word)]
+		if !exists || cachedData.AudioURL == "" {
+			continue
+		}
+		writer.WriteString(capitalizePhrase(word) + "\t" + cachedData.AudioURL + "\n")
+		count++
+	}
+	if err := writer.Flush(); err != nil {
+		return count, err
+	}
+	return count, file.Close()
+}
@@ -0,0 +1,49 @@
+package classifier
+
+import (
+	"log"
+	"os"
+	"sync"
+)
+
+const cacheChangeLogPath = "cache_changes.log"
+
+var (
+	cacheChangeFile   *os.File
+	cacheChangeLogger *log.Logger
+	cacheChangeMutex  sync.Mutex
+)
+
+// Open cache_changes.log for appending and mark the start of this run
+func openCacheChangeLog() {
+	file, err := os.OpenFile(cacheChangeLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		log.Printf("Error opening %s: %v\n", cacheChangeLogPath, err)
+		return
+	}
+	cacheChangeFile = file
+	cacheChangeLogger = log.New(file, "", log.LstdFlags)
+	cacheChangeLogger.Printf("=== Run started (%d cached, %d unknown) ===\n", len(wordCache), len(wordUnknown))
+}
+
+func closeCacheChangeLog() {
+	if cacheChangeFile == nil {
+		return
+	}
+	cacheChangeLogger.Printf("=== Run finished (%d cached, %d unknown) ===\n", len(wordCache), len(wordUnknown))
+	cacheChangeFile.Close()
+}
+
+// Record an added, updated or evicted entry of wordCache or wordUnknown
+func logCacheChange(action string, store string, word string, detail string) {
+	if cacheChangeLogger == nil {
+		return
+	}
+	cacheChangeMutex.Lock()
+	defer cacheChangeMutex.Unlock()
+	if detail != "" {
+		cacheChangeLogger.Printf("%-7s %-11s %s (%s)\n", action, store, word, detail)
+	} else {
+		cacheChangeLogger.Printf("%-7s %-11s %s\n", action, store, word)
+	}
+}
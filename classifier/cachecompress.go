@@ -0,0 +1,62 @@
+package classifier
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Path the word cache is saved to: cachePath, or cachePath with a .gz extension
+// when compressCache is set
+func wordCachePath() string {
+	if performanceConfig.CompressCache {
+		return cachePath + ".gz"
+	}
+	return cachePath
+}
+
+// The word cache in the format not configured, read once and removed on the next
+// save so the cache switches format when compressCache changes
+func otherWordCachePath() string {
+	if performanceConfig.CompressCache {
+		return cachePath
+	}
+	return cachePath + ".gz"
+}
+
+// Contents of a cache file read from path, decompressed when the name ends in .gz
+func decompressCacheData(path string, data []byte) ([]byte, error) {
+	if !strings.HasSuffix(path, ".gz") {
+		return data, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+// Write a cache file atomically, compressing it when the name ends in .gz
+func writeCacheFile(path string, data []byte) error {
+	if strings.HasSuffix(path, ".gz") {
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		if _, err := writer.Write(data); err != nil {
+			return err
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+		data = compressed.Bytes()
+	}
+	return writeFileAtomic(path, data)
+}
+
+// Whether a file exists at path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,47 @@
+package classifier
+
+import (
+	"time"
+)
+
+// Unsaved changes to wordCache and wordUnknown, guarded by cacheMutex
+var (
+	wordCacheDirty   bool
+	wordUnknownDirty bool
+	unsavedChanges   int
+	lastCacheFlush   = time.Now()
+)
+
+// Note changed cache entries and save the caches once CacheFlushEvery changes
+// have piled up or CacheFlushSeconds have passed since the last save. Call
+// without holding cacheMutex.
+func markCachesDirty(cacheChanged bool, unknownChanged bool) {
+	cacheMutex.Lock()
+	wordCacheDirty = wordCacheDirty || cacheChanged
+	wordUnknownDirty = wordUnknownDirty || unknownChanged
+	unsavedChanges++
+	due := unsavedChanges >= performanceConfig.CacheFlushEvery ||
+		time.Since(lastCacheFlush) >= time.Duration(performanceConfig.CacheFlushSeconds)*time.Second
+	cacheMutex.Unlock()
+
+	if due {
+		flushCaches()
+	}
+}
+
+// Save whichever of word_cache.json and word_unknown.json has unsaved changes
+func flushCaches() {
+	cacheMutex.Lock()
+	saveCache, saveUnknown := wordCacheDirty, wordUnknownDirty
+	wordCacheDirty, wordUnknownDirty = false, false
+	unsavedChanges = 0
+	lastCacheFlush = time.Now()
+	cacheMutex.Unlock()
+
+	if saveCache {
+		saveWordCache()
+	}
+	if saveUnknown {
+		saveWordUnknown()
+	}
+}
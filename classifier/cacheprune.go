@@ -0,0 +1,85 @@
+package classifier
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// PruneOptions selects the word_cache.json entries removed by PruneCache. An
+// entry is removed when it matches any of the set criteria.
+type PruneOptions struct {
+	OlderThan time.Duration // Remove entries cached longer ago than this; entries without a timestamp count as old. Zero keeps every age.
+	Empty     bool          // Remove entries without definitions
+	KeepList  string        // Word list file, one word per line; remove every word not in it. Empty keeps every word.
+}
+
+// PruneCache removes the selected entries from the word cache and rewrites
+// word_cache.json atomically. Returns the number of entries removed and kept.
+func (c *Classifier) PruneCache(opts PruneOptions) (int, int, error) {
+	var keep map[string]bool
+	if opts.KeepList != "" {
+		words, err := loadWordList(opts.KeepList)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to read keep list %s: %v", opts.KeepList, err)
+		}
+		keep = make(map[string]bool, len(words))
+		for _, word := range words {
+			keep[word] = true
+		}
+	}
+
+	cacheMutex.Lock()
+	removed := 0
+	for word, entry := range wordCache {
+		reason := pruneReason(word, entry, opts, keep)
+		if reason == "" {
+			continue
+		}
+		delete(wordCache, word)
+		logCacheChange("evicted", "wordCache", word, reason)
+		removed++
+	}
+	kept := len(wordCache)
+	cacheMutex.Unlock()
+
+	if err := writeWordCache(); err != nil {
+		return removed, kept, fmt.Errorf("failed to write %s: %v", wordCachePath(), err)
+	}
+	return removed, kept, nil
+}
+
+// Why an entry is pruned, or "" to keep it
+func pruneReason(word string, entry WordCache, opts PruneOptions, keep map[string]bool) string {
+	switch {
+	case keep != nil && !keep[word]:
+		return "pruned, not in keep list"
+	case opts.Empty && len(entry.Definitions) == 0:
+		return "pruned, no definitions"
+	case opts.OlderThan > 0 && (entry.CachedAt.IsZero() || time.Since(entry.CachedAt) > opts.OlderThan):
+		return fmt.Sprintf("pruned, older than %s", opts.OlderThan)
+	}
+	return ""
+}
+
+// Replace path with data through a temporary file in the same directory, so an
+// interrupted write never leaves a truncated file behind
+func writeFileAtomic(path string, data []byte) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(temp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
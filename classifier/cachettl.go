@@ -0,0 +1,54 @@
+package classifier
+
+import (
+	"fmt"
+	"time"
+)
+
+// Maximum age of a cached entry before it is looked up again; zero keeps entries forever
+var cacheTTL time.Duration
+
+// Age after which a word marked unknown is queried again; zero never retries it
+var unknownRetryAfter time.Duration
+
+// Parse the CacheTTL setting, e.g. "720h". An empty value or "0" disables expiry.
+func parseCacheTTL(value string) (time.Duration, error) {
+	return parseExpiry("cacheTTL", value)
+}
+
+// Parse the UnknownRetryAfter setting, e.g. "720h". An empty value or "0" never retries.
+func parseUnknownRetryAfter(value string) (time.Duration, error) {
+	return parseExpiry("unknownRetryAfter", value)
+}
+
+func parseExpiry(name string, value string) (time.Duration, error) {
+	if value == "" || value == "0" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", name, err)
+	}
+	if ttl < 0 {
+		return 0, fmt.Errorf("%s must not be negative: %s", name, value)
+	}
+	return ttl, nil
+}
+
+// Whether a cached entry is older than the TTL. Entries cached before timestamps
+// were recorded have no CachedAt and count as stale once a TTL is set.
+func isCacheEntryStale(entry WordCache) bool {
+	if cacheTTL <= 0 {
+		return false
+	}
+	return entry.CachedAt.IsZero() || time.Since(entry.CachedAt) > cacheTTL
+}
+
+// Whether a word marked unknown is due for another lookup. Words marked before
+// timestamps were recorded count as due once UnknownRetryAfter is set.
+func isUnknownStale(entry unknownEntry) bool {
+	if unknownRetryAfter <= 0 {
+		return false
+	}
+	return entry.MarkedAt.IsZero() || time.Since(entry.MarkedAt) > unknownRetryAfter
+}
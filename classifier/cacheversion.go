@@ -0,0 +1,156 @@
+package classifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+	"time"
+)
+
+// Schema version written to word_cache.json. Version 1 is the bare map of words
+// to entries used before the file carried a version header.
+const wordCacheVersion = 2
+
+// Layout of word_cache.json from version 2 on
+type wordCacheFile struct {
+	Version int                  `json:"version"`
+	Words   map[string]WordCache `json:"words"`
+}
+
+// Decode a word cache in any schema version. Entries that no longer unmarshal into
+// WordCache are salvaged field by field instead of failing the whole file. The
+// returned notes describe every upgrade and salvaged or dropped entry.
+func decodeWordCache(data []byte) (map[string]WordCache, int, []string, error) {
+	var header struct {
+		Version int                        `json:"version"`
+		Words   map[string]json.RawMessage `json:"words"`
+	}
+	version := 1
+	var raw map[string]json.RawMessage
+	// A version 1 cache holding the words "version" or "words" fails this decode
+	// or lacks a positive version, so it is still read as a bare map
+	if err := json.Unmarshal(data, &header); err == nil && header.Version > 0 && header.Words != nil {
+		version = header.Version
+		raw = header.Words
+	} else if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, 0, nil, err
+	}
+
+	var notes []string
+	if version > wordCacheVersion {
+		notes = append(notes, fmt.Sprintf("version %d is newer than supported version %d, unknown fields are dropped", version, wordCacheVersion))
+	}
+	words := make(map[string]WordCache, len(raw))
+	for word, entryData := range raw {
+		var entry WordCache
+		if err := json.Unmarshal(entryData, &entry); err == nil {
+			words[word] = entry
+			continue
+		}
+		entry, dropped, err := salvageWordCacheEntry(entryData)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("dropped '%s': %v", word, err))
+			continue
+		}
+		words[word] = entry
+		if len(dropped) > 0 {
+			notes = append(notes, fmt.Sprintf("migrated '%s', dropped fields: %s", word, strings.Join(dropped, ", ")))
+		} else {
+			notes = append(notes, fmt.Sprintf("migrated '%s'", word))
+		}
+	}
+	return words, version, notes, nil
+}
+
+// Decode an entry field by field, keeping every field that still fits WordCache.
+// Definitions stored as plain strings are converted to Definition values.
+func salvageWordCacheEntry(data []byte) (WordCache, []string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return WordCache{}, nil, fmt.Errorf("entry is not an object")
+	}
+
+	var entry WordCache
+	var dropped []string
+	for name, value := range fields {
+		field, _ := json.Marshal(map[string]json.RawMessage{name: value})
+		if err := json.Unmarshal(field, &entry); err == nil {
+			continue
+		}
+		if strings.EqualFold(name, "Definitions") {
+			var texts []string
+			if err := json.Unmarshal(value, &texts); err == nil {
+				for _, text := range texts {
+					entry.Definitions = append(entry.Definitions, Definition{Definition: text})
+				}
+				continue
+			}
+		}
+		dropped = append(dropped, name)
+	}
+	return entry, dropped, nil
+}
+
+// Read word_cache.json, upgrading older versions. The original file is kept as a
+// backup before anything is rewritten, and a file that cannot be read at all is
+// set aside instead of being overwritten by an empty cache. The result reports
+// whether the cache should be saved in the current version.
+func loadVersionedWordCache(path string) (map[string]WordCache, bool) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return make(map[string]WordCache), false
+	}
+
+	// Backups keep the file as it was on disk, compressed or not
+	var words map[string]WordCache
+	var version int
+	var notes []string
+	contents, err := decompressCacheData(path, data)
+	if err == nil {
+		words, version, notes, err = decodeWordCache(contents)
+	}
+	if err != nil {
+		backup := path + ".corrupt"
+		ioutil.WriteFile(backup, data, 0644)
+		Statusf("Could not read %s (%v); starting with an empty cache, original kept as %s\n", path, err, backup)
+		return make(map[string]WordCache), false
+	}
+	if version == wordCacheVersion && len(notes) == 0 {
+		return words, false
+	}
+
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if err := ioutil.WriteFile(backup, data, 0644); err != nil {
+		log.Printf("Error backing up %s: %v\n", path, err)
+	}
+	Statusf("Migrated %s from version %d to %d (%d entries), original kept as %s\n", path, version, wordCacheVersion, len(words), backup)
+	for _, note := range notes {
+		log.Printf("Cache migration: %s\n", note)
+	}
+	return words, true
+}
+
+// Entry of word_unknown.json: when the word was last found to have no definitions
+type unknownEntry struct {
+	MarkedAt time.Time `json:"markedAt"`
+}
+
+// Decode word_unknown.json. The earlier format mapped each word to true; those
+// words are migrated without a timestamp, so reports true for migrated.
+func decodeWordUnknown(data []byte) (map[string]unknownEntry, bool, error) {
+	var unknown map[string]unknownEntry
+	if err := json.Unmarshal(data, &unknown); err == nil {
+		return unknown, false, nil
+	}
+	var legacy map[string]bool
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return nil, false, err
+	}
+	unknown = make(map[string]unknownEntry, len(legacy))
+	for word := range legacy {
+		unknown[word] = unknownEntry{}
+	}
+	return unknown, true, nil
+}
@@ -0,0 +1,184 @@
+package classifier
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// An output category: the Penn Treebank tags it collects and, optionally, a word
+// list file whose words are placed in it whatever their tag. Words of a category
+// that skips lookups are listed as found, without dictionary requests.
+type CategoryConfig struct {
+	Name       string   `yaml:"name"`
+	Tags       []string `yaml:"tags"`
+	WordList   string   `yaml:"wordList"`
+	SkipLookup bool     `yaml:"skipLookup"`
+}
+
+// The part-of-speech categories used when none are configured. Names and places
+// rarely have dictionary entries, so ProperNouns skips lookups. OtherWords lists
+// no tags, so it collects every word the other categories don't.
+var defaultCategories = []CategoryConfig{
+	{Name: "Nouns", Tags: []string{"NN", "NNS"}},
+	{Name: "ProperNouns", Tags: []string{"NNP", "NNPS"}, SkipLookup: true},
+	{Name: "Verbs", Tags: []string{"VB", "VBD", "VBP", "VBZ", "VBG"}},
+	{Name: "Adjectives", Tags: []string{"JJ", "JJR", "JJS"}},
+	{Name: "Adverbs", Tags: []string{"RB", "RBR", "RBS"}},
+	{Name: "OtherWords"},
+}
+
+var (
+	categoryByTag    map[string]string // Category collecting each POS tag
+	categoryByWord   map[string]string // Category of each word from a category word list
+	fallbackCategory string            // Category for tags no other category lists; empty drops those words
+	skipLookup       map[string]bool   // Categories whose words are listed without dictionary lookups
+	categoriesKey    string            // Digest of the category setup, part of the token cache key
+)
+
+// Dictionary parts of speech and the POS tag whose category they belong to
+var partOfSpeechTags = map[string]string{
+	"noun":        "NN",
+	"proper noun": "NNP",
+	"verb":        "VB",
+	"adjective":   "JJ",
+	"adverb":      "RB",
+}
+
+// Set up the configured categories, or the default ones, and load their word
+// lists. Tags may belong to one category only. A category listing neither tags nor
+// a word list is the fallback for unlisted tags; there may be at most one.
+func setupCategories() error {
+	categories := config.Categories
+	if len(categories) == 0 {
+		categories = defaultCategories
+	}
+
+	names := make([]string, 0, len(categories))
+	byTag := make(map[string]string)
+	byWord := make(map[string]string)
+	skip := make(map[string]bool)
+	fallback := ""
+	var digest []string
+	for _, category := range categories {
+		name := strings.TrimSpace(category.Name)
+		if name == "" {
+			return fmt.Errorf("category without a name")
+		}
+		if strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("category name %q must not contain path separators", name)
+		}
+		for _, existing := range names {
+			if existing == name {
+				return fmt.Errorf("category %s is defined twice", name)
+			}
+		}
+		names = append(names, name)
+		if category.SkipLookup {
+			skip[name] = true
+		}
+
+		for _, tag := range category.Tags {
+			tag = strings.ToUpper(strings.TrimSpace(tag))
+			if other, ok := byTag[tag]; ok {
+				return fmt.Errorf("tag %s is listed under both %s and %s", tag, other, name)
+			}
+			byTag[tag] = name
+		}
+
+		var words []string
+		if category.WordList != "" {
+			var err error
+			words, err = loadWordList(category.WordList)
+			if err != nil {
+				return fmt.Errorf("word list of category %s: %v", name, err)
+			}
+			// A word in several lists stays in the first category
+			for _, word := range words {
+				if _, ok := byWord[word]; !ok {
+					byWord[word] = name
+				}
+			}
+		}
+
+		if len(category.Tags) == 0 && category.WordList == "" {
+			if fallback != "" {
+				return fmt.Errorf("categories %s and %s both list no tags", fallback, name)
+			}
+			fallback = name
+		}
+
+		sort.Strings(words)
+		digest = append(digest, name+"="+strings.Join(category.Tags, ",")+"="+strings.Join(words, ","))
+	}
+
+	categoryNames = names
+	categoryByTag = byTag
+	categoryByWord = byWord
+	fallbackCategory = fallback
+	skipLookup = skip
+	sum := sha256.Sum256([]byte(strings.Join(digest, "\n")))
+	categoriesKey = hex.EncodeToString(sum[:8])
+	return nil
+}
+
+// Map a Penn Treebank POS tag to its output category
+func categoryForTag(tag string) string {
+	if category, ok := categoryByTag[tag]; ok {
+		return category
+	}
+	return fallbackCategory
+}
+
+// Category of a word with the given tag, preferring a category word list that
+// contains it. An empty result means no category collects the word.
+func categoryForWord(word string, tag string) string {
+	if category, ok := categoryByWord[word]; ok {
+		return category
+	}
+	return categoryForTag(tag)
+}
+
+// Map a dictionary part of speech ("noun", "verb", ...) to its output category
+func categoryForPartOfSpeech(partOfSpeech string) string {
+	if tag, ok := partOfSpeechTags[strings.ToLower(partOfSpeech)]; ok {
+		return categoryForTag(tag)
+	}
+	return fallbackCategory
+}
+
+// With CategoryDefinitionsOnly, the definitions whose part of speech belongs to
+// category. Words whose definitions all belong elsewhere, and the AllWords list,
+// keep every definition.
+func definitionsForCategory(definitions []Definition, category string) []Definition {
+	if !config.CategoryDefinitionsOnly || category == "" {
+		return definitions
+	}
+	var matching []Definition
+	for _, def := range definitions {
+		if categoryForPartOfSpeech(def.PartOfSpeech) == category {
+			matching = append(matching, def)
+		}
+	}
+	if len(matching) == 0 {
+		return definitions
+	}
+	return matching
+}
+
+// Words of the categories that are looked up in the dictionary. A word that is
+// also in a category skipping lookups is still looked up for the other one.
+func lookupWordSet(categorizedWords map[string][]string) map[string]bool {
+	words := make(map[string]bool)
+	for category, categoryWords := range categorizedWords {
+		if skipLookup[category] {
+			continue
+		}
+		for _, word := range categoryWords {
+			words[word] = true
+		}
+	}
+	return words
+}
@@ -0,0 +1,130 @@
+package classifier
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// CEFR levels from easiest to hardest
+var cefrLevels = []string{"A1", "A2", "B1", "B2", "C1", "C2"}
+
+// Level index (0 for A1 through 5 for C2) of each word in the CEFR word list
+var cefrWordLevels map[string]int
+
+func cefrLevelIndex(level string) int {
+	for i, name := range cefrLevels {
+		if strings.EqualFold(level, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Load a CEFR word list with one "word level" pair per line, e.g. "apple A1".
+// Words and levels may be separated by spaces, tabs or a comma; # starts a comment.
+func loadCEFRList(path string) (map[string]int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	levels := make(map[string]int)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected a word and a level", lineNumber)
+		}
+		level := cefrLevelIndex(fields[len(fields)-1])
+		if level < 0 {
+			return nil, fmt.Errorf("line %d: unknown CEFR level '%s'", lineNumber, fields[len(fields)-1])
+		}
+		word := lowerWord(strings.Join(fields[:len(fields)-1], " "))
+		// Keep the easiest level when a word is listed more than once
+		if existing, ok := levels[word]; !ok || level < existing {
+			levels[word] = level
+		}
+	}
+	return levels, scanner.Err()
+}
+
+// Words in the definition rated above MaxDefinitionLevel. Words missing from the
+// list are not rated and never count as hard. An unset level means B1.
+func hardDefinitionWords(definition string) map[string]bool {
+	level := config.MaxDefinitionLevel
+	if level == "" {
+		level = "B1"
+	}
+	maxLevel := cefrLevelIndex(level)
+	hard := make(map[string]bool)
+	if maxLevel < 0 {
+		return hard
+	}
+	words := strings.FieldsFunc(definition, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for _, word := range words {
+		word = lowerWord(word)
+		if level, ok := cefrWordLevels[word]; ok && level > maxLevel {
+			hard[word] = true
+		}
+	}
+	return hard
+}
+
+// Drop definitions that use words above the configured level. If every
+// definition is too hard they are all kept so the word isn't left empty.
+func filterHardDefinitions(definitions []Definition) []Definition {
+	var simple []Definition
+	for _, def := range definitions {
+		if len(hardDefinitionWords(def.Definition)) == 0 {
+			simple = append(simple, def)
+		}
+	}
+	if len(simple) == 0 {
+		return definitions
+	}
+	return simple
+}
+
+// Wrap words above the configured level in asterisks, e.g. "a *nocturnal* animal"
+func markHardWords(definition string) string {
+	hard := hardDefinitionWords(definition)
+	if len(hard) == 0 {
+		return definition
+	}
+
+	var output strings.Builder
+	var word strings.Builder
+	flush := func() {
+		if word.Len() == 0 {
+			return
+		}
+		if hard[lowerWord(word.String())] {
+			output.WriteString("*" + word.String() + "*")
+		} else {
+			output.WriteString(word.String())
+		}
+		word.Reset()
+	}
+	for _, r := range definition {
+		if unicode.IsLetter(r) || r == '\'' {
+			word.WriteRune(r)
+			continue
+		}
+		flush()
+		output.WriteRune(r)
+	}
+	flush()
+	return output.String()
+}
@@ -0,0 +1,30 @@
+package classifier
+
+import "testing"
+
+func TestHardDefinitionWordsDefaultsToB1(t *testing.T) {
+	savedConfig, savedLevels := config, cefrWordLevels
+	defer func() { config, cefrWordLevels = savedConfig, savedLevels }()
+
+	config = OutputConfig{}
+	cefrWordLevels = map[string]int{
+		"small":      cefrLevelIndex("A1"),
+		"animal":     cefrLevelIndex("B1"),
+		"carnivore":  cefrLevelIndex("C1"),
+		"vulpine":    cefrLevelIndex("C2"),
+		"nocturnal":  cefrLevelIndex("B2"),
+		"frequently": cefrLevelIndex("B1"),
+	}
+
+	hard := hardDefinitionWords("A small, frequently nocturnal carnivore; any vulpine animal.")
+	for _, word := range []string{"nocturnal", "carnivore", "vulpine"} {
+		if !hard[word] {
+			t.Errorf("%q above B1 was not flagged", word)
+		}
+	}
+	for _, word := range []string{"small", "frequently", "animal", "any"} {
+		if hard[word] {
+			t.Errorf("%q at or below B1, or unrated, was flagged", word)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+// Package classifier sorts the words of text files into part-of-speech
+// categories, looks them up in online or offline dictionaries and writes word
+// lists, explanations and example sentences for each category.
+//
+// Settings, caches and tokenizer state are package-level, so a program should use
+// one Classifier at a time: New replaces the settings of any earlier Classifier.
+package classifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Classifier runs the classification with the settings it was created with.
+// Close it to save the word caches.
+type Classifier struct {
+	closeOnce   sync.Once // Close may be called again from a signal handler while closing
+	interrupted bool      // A run stopped early, so the token cache still needs saving
+}
+
+// Result describes a processed directory: the known words of each category, most
+// frequent first, the words no dictionary knows, each word's corpus count and the
+// run's statistics.
+type Result struct {
+	OutputDir    string
+	Files        []string
+	Categories   map[string][]string
+	UnknownWords []string
+	Frequencies  map[string]int
+	Stats        RunStats
+}
+
+// FileResult holds the words of a single input file by category, one entry per
+// occurrence, and each word's count
+type FileResult struct {
+	Categories  map[string][]string
+	Frequencies map[string]int
+}
+
+// Option customizes a Classifier created by New
+type Option func(*options)
+
+type options struct {
+	outputConfigPath  string
+	outputConfig      *OutputConfig
+	queryConfig       *QueryConfig
+	performanceConfig *PerformanceConfig
+	proxyConfig       *ProxyConfig
+	resume            bool
+	force             bool
+	progress          ProgressFunc
+}
+
+// WithOutputConfigFile reads the output settings from path instead of outputConfig.yml
+func WithOutputConfigFile(path string) Option {
+	return func(o *options) { o.outputConfigPath = path }
+}
+
+// WithOutputConfig uses the given output settings instead of reading a file
+func WithOutputConfig(cfg OutputConfig) Option {
+	return func(o *options) { o.outputConfig = &cfg }
+}
+
+// WithQueryConfig uses the given dictionary settings instead of queryConfig.yml
+func WithQueryConfig(cfg QueryConfig) Option {
+	return func(o *options) { o.queryConfig = &cfg }
+}
+
+// WithPerformanceConfig uses the given settings instead of performanceConfig.yml
+func WithPerformanceConfig(cfg PerformanceConfig) Option {
+	return func(o *options) { o.performanceConfig = &cfg }
+}
+
+// WithProxyConfig uses the given proxy settings instead of proxy.yml
+func WithProxyConfig(cfg ProxyConfig) Option {
+	return func(o *options) { o.proxyConfig = &cfg }
+}
+
+// WithResume continues an interrupted run in the output directory, keeping the
+// words already written. force regenerates everything even when resuming.
+func WithResume(resume bool, force bool) Option {
+	return func(o *options) { o.resume, o.force = resume, force }
+}
+
+// New loads and checks the settings, the dictionaries and the caches. Settings
+// not given as options are read from the YAML files in the working directory.
+func New(opts ...Option) (*Classifier, error) {
+	o := options{outputConfigPath: outputConfigPath}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	outputConfigPath = o.outputConfigPath
+	resumeRun, forceRun = o.resume, o.force
+	progressHandler = o.progress
+
+	if o.outputConfig != nil {
+		config = *o.outputConfig
+	} else {
+		config = loadConfig()
+	}
+	morphologyTransform = selectMorphology(config.Morphology)
+	contractionMode = selectContractions(config.Contractions)
+	checkStatusOutput()
+	config.DuplicateDefinitions = selectDuplicateDefinitions(config.DuplicateDefinitions)
+	localeTag = selectLocale(config.Locale)
+	if err := compileExplanationTemplates(); err != nil {
+		return nil, fmt.Errorf("invalid outputConfig.yml: %v", err)
+	}
+	if err := compileAnkiTemplates(); err != nil {
+		return nil, fmt.Errorf("invalid outputConfig.yml: %v", err)
+	}
+	if err := loadStopwords(); err != nil {
+		return nil, fmt.Errorf("failed to load stopwords file %s: %v", config.StopwordsFile, err)
+	}
+	if err := loadWordFilters(); err != nil {
+		return nil, fmt.Errorf("failed to load include or exclude words: %v", err)
+	}
+	if err := checkInputExtensions(); err != nil {
+		return nil, fmt.Errorf("invalid outputConfig.yml: %v", err)
+	}
+	if err := setupCategories(); err != nil {
+		return nil, fmt.Errorf("invalid categories in outputConfig.yml: %v", err)
+	}
+
+	if o.queryConfig != nil {
+		queryConfig = *o.queryConfig
+	} else {
+		queryConfig = loadQueryConfig()
+	}
+	if err := validateSources(queryConfig.Sources); err != nil {
+		return nil, fmt.Errorf("invalid queryConfig.yml: %v", err)
+	}
+	ttl, err := parseCacheTTL(queryConfig.CacheTTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid queryConfig.yml: %v", err)
+	}
+	cacheTTL = ttl
+	retryAfter, err := parseUnknownRetryAfter(queryConfig.UnknownRetryAfter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid queryConfig.yml: %v", err)
+	}
+	unknownRetryAfter = retryAfter
+	if err := setupLanguage(); err != nil {
+		return nil, fmt.Errorf("invalid language settings: %v", err)
+	}
+	if usesOfflineDictionary() {
+		entries, err := loadOfflineDictionary(queryConfig.OfflineDictionary)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load offline dictionary: %v", err)
+		}
+		offlineEntries = entries
+		log.Printf("Loaded offline dictionary with %d words\n", len(entries))
+	}
+
+	if o.performanceConfig != nil {
+		performanceConfig = *o.performanceConfig
+	} else {
+		performanceConfig = loadPerformanceConfig()
+	}
+	if o.proxyConfig != nil {
+		proxyConfig = *o.proxyConfig
+	} else {
+		proxyConfig = loadProxyConfig()
+	}
+	requestTimeout = parseRequestTimeout(proxyConfig.RequestTimeout)
+	httpClient = createHTTPClient()
+
+	loadWordCache()
+	loadWordUnknown()
+	if config.CEFRWordList != "" && config.DefinitionLevelMode != "" {
+		levels, err := loadCEFRList(config.CEFRWordList)
+		if err != nil {
+			Statusf("Error loading CEFR word list %s: %v\n", config.CEFRWordList, err)
+		} else {
+			cefrWordLevels = levels
+		}
+	}
+	if config.LogCacheChanges {
+		openCacheChangeLog()
+	}
+	if config.CacheTokenization {
+		loadTokenCache()
+	}
+	return &Classifier{}, nil
+}
+
+// ProcessDirectory classifies every input file of inputDir and writes the output
+// files to outputDir, by default the outputDirectory setting or "<input directory
+// name>_ewClassifiers". When ctx is cancelled or its deadline passes, or
+// Interrupt is called, the run stops after the current word or request and
+// returns an error wrapping ErrInterrupted. The output written so far can be
+// completed with WithResume.
+func (c *Classifier) ProcessDirectory(ctx context.Context, inputDir string, outputDir string) (*Result, error) {
+	ctx, cancel := startRun(ctx)
+	defer cancel()
+	result, err := processAllFiles(ctx, inputDir, outputDir)
+	if errors.Is(err, ErrInterrupted) {
+		c.interrupted = true
+	}
+	return result, err
+}
+
+// ProcessFile tokenizes and categorizes a single input file without dictionary
+// lookups or output files
+func (c *Classifier) ProcessFile(ctx context.Context, path string) (*FileResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if proseModel == nil {
+		if err := checkProseModels(); err != nil {
+			return nil, fmt.Errorf("failed to initialize the prose NLP models: %v", err)
+		}
+	}
+	categorizedWords, words, err := processFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FileResult{Categories: categorizedWords, Frequencies: words}, nil
+}
+
+// DryRun reports how many words each category of inputDir would get and how many
+// of them the caches cover, without dictionary requests or output files
+func (c *Classifier) DryRun(ctx context.Context, inputDir string, outputDir string) error {
+	return dryRun(ctx, inputDir, ResolveOutputDir(inputDir, outputDir))
+}
+
+// LookupWord returns the explanation of a single word as written to the _ex
+// files. A *LookupError means the dictionary couldn't be reached or ctx was
+// cancelled during the request.
+func (c *Classifier) LookupWord(ctx context.Context, word string) (string, error) {
+	return fetchWordDetails(ctx, word, "")
+}
+
+// CacheEntry returns the word's entry in word_cache.json, if it has one
+func (c *Classifier) CacheEntry(word string) (WordCache, bool) {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	entry, exists := wordCache[lowerWord(word)]
+	return entry, exists
+}
+
+// UnknownSince returns when the word was marked unknown in word_unknown.json. The
+// time is zero for words marked before timestamps were recorded.
+func (c *Classifier) UnknownSince(word string) (time.Time, bool) {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	entry, isUnknown := wordUnknown[lowerWord(word)]
+	return entry.MarkedAt, isUnknown
+}
+
+// Close saves the word caches, and the token cache of an interrupted run, and
+// closes the cache change log. Later and concurrent calls wait for the first one
+// and do nothing.
+func (c *Classifier) Close() error {
+	c.closeOnce.Do(func() {
+		flushCaches()
+		if c.interrupted && config.CacheTokenization {
+			saveTokenCache()
+		}
+		closeCacheChangeLog()
+	})
+	return nil
+}
+
+// ResolveOutputDir returns outputDir, or when it is empty the outputDirectory
+// setting or the default output directory named after inputDir
+func ResolveOutputDir(inputDir string, outputDir string) string {
+	return resolveOutputDir(inputDir, outputDir)
+}
@@ -0,0 +1,102 @@
+package classifier
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// File name of the combined report for the configured format
+func combinedReportName() string {
+	if config.CombinedFormat == "markdown" {
+		return "Report.md"
+	}
+	return "Report.txt"
+}
+
+// Drop the first line of a block, the word heading that the report writes itself,
+// and end what remains with a newline
+func withoutFirstLine(block string) string {
+	index := strings.Index(block, "\n")
+	if index < 0 {
+		return ""
+	}
+	rest := block[index+1:]
+	if rest != "" && !strings.HasSuffix(rest, "\n") {
+		rest += "\n"
+	}
+	return rest
+}
+
+// Write every category's known words to a single report: each word with its
+// frequency, the explanation block of the _ex files and the example sentences
+// of the _es files. Returns the number of words written.
+func writeCombinedReport(ctx context.Context, path string, wordsByCategory map[string][]string, frequencies map[string]int) (int, error) {
+	file, err := createOutputFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	markdown := config.CombinedFormat == "markdown"
+	writer := bufio.NewWriter(file)
+	if markdown {
+		writer.WriteString("# Vocabulary Report\n")
+	}
+
+	written := 0
+	for _, category := range categoryNames {
+		words := wordsByCategory[category]
+		if len(words) == 0 {
+			continue
+		}
+		if markdown {
+			writer.WriteString(fmt.Sprintf("\n## %s\n", category))
+		} else {
+			if written > 0 {
+				writer.WriteString("\n")
+			}
+			writer.WriteString(fmt.Sprintf("===== %s =====\n", category))
+		}
+
+		for _, word := range words {
+			if ctx.Err() != nil {
+				break
+			}
+			wordDetails, err := fetchWordDetails(ctx, word, category)
+			if err != nil {
+				continue
+			}
+			explanation := withoutFirstLine(formatExplanation(word, category, wordDetails))
+			examples := withoutFirstLine(generateExampleSentencesContent(word))
+
+			heading := fmt.Sprintf("%s (%d)", capitalizePhrase(word), frequencies[lowerWord(word)])
+			if markdown {
+				writer.WriteString(fmt.Sprintf("\n### %s\n\n```text\n%s", heading, explanation))
+				if examples != "" {
+					writer.WriteString("Examples:\n" + examples)
+				}
+				writer.WriteString("```\n")
+			} else {
+				writer.WriteString(fmt.Sprintf("\n%s\n%s", heading, explanation))
+				if examples != "" {
+					writer.WriteString(indent(1) + "Examples:\n" + indentLines(examples))
+				}
+			}
+			written++
+		}
+	}
+	return written, writer.Flush()
+}
+
+// Indent every line of a block by one more level
+func indentLines(block string) string {
+	var output strings.Builder
+	for _, line := range strings.SplitAfter(block, "\n") {
+		if line != "" {
+			output.WriteString(indent(1) + line)
+		}
+	}
+	return output.String()
+}
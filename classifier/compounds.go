@@ -0,0 +1,133 @@
+package classifier
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jdkato/prose/v2"
+)
+
+// Tags of words tagged on their own, see tagWord
+var wordTags = make(map[string]string)
+
+// Part-of-speech tag of a word tagged without its sentence, as for forced words
+// and the parts of split compounds
+func tagWord(word string) string {
+	if tag, ok := wordTags[word]; ok {
+		return tag
+	}
+	tag := ""
+	if doc, err := newProseDocument(word); err == nil && len(doc.Tokens()) > 0 {
+		tag = doc.Tokens()[0].Tag
+	}
+	wordTags[word] = tag
+	return tag
+}
+
+// Parts of a hyphenated compound ("well-being" -> well, being), or nil unless
+// every part is word text
+func compoundParts(word string) []string {
+	if !strings.Contains(word, "-") {
+		return nil
+	}
+	parts := strings.Split(word, "-")
+	for _, part := range parts {
+		if !isWordText(part) {
+			return nil
+		}
+	}
+	return parts
+}
+
+// Apply the HyphenatedWords setting to the words of a token with the given tag.
+// keep passes compounds through whole, split replaces them with their parts
+// tagged on their own, and none leaves them to be rejected as non-word text.
+func splitCompounds(words []string, tag string) []prose.Token {
+	var result []prose.Token
+	for _, word := range words {
+		parts := compoundParts(word)
+		if parts == nil || config.HyphenatedWords != "split" {
+			result = append(result, prose.Token{Text: word, Tag: tag})
+			continue
+		}
+		for _, part := range parts {
+			result = append(result, prose.Token{Text: part, Tag: tagWord(part)})
+		}
+	}
+	return result
+}
+
+// Whether a word is counted: word text, or a hyphenated compound when compounds are kept
+func isCountedWord(word string) bool {
+	if isWordText(word) {
+		return true
+	}
+	return config.HyphenatedWords == "keep" && compoundParts(word) != nil
+}
+
+// Replace the compounds the dictionary doesn't know with their parts, which take
+// over the compound's count. Parts are categorized by their own tag; stopwords
+// and parts no category collects are dropped.
+func splitUnknownCompounds(ctx context.Context, categorizedWords map[string][]string, allWords map[string]int) (map[string][]string, map[string]int) {
+	lookupWords := lookupWordSet(categorizedWords)
+	var compounds []string
+	for word := range allWords {
+		if lookupWords[word] && compoundParts(word) != nil {
+			compounds = append(compounds, word)
+		}
+	}
+	if len(compounds) == 0 {
+		return categorizedWords, allWords
+	}
+
+	prefetchWordDetails(ctx, compounds)
+
+	unknown := make(map[string]bool)
+	cacheMutex.Lock()
+	for _, word := range compounds {
+		if cachedData, ok := wordCache[lowerWord(word)]; !ok || len(cachedData.Definitions) == 0 {
+			unknown[word] = true
+		}
+	}
+	cacheMutex.Unlock()
+	if len(unknown) == 0 {
+		return categorizedWords, allWords
+	}
+
+	// Categorized parts of each unknown compound
+	type compoundPart struct{ word, category string }
+	replacements := make(map[string][]compoundPart)
+	for word := range unknown {
+		for _, part := range compoundParts(word) {
+			if isStopword(part) {
+				continue
+			}
+			tag := tagWord(part)
+			part = morphologyTransform(part, tag)
+			if category := categoryForWord(part, tag); category != "" {
+				replacements[word] = append(replacements[word], compoundPart{part, category})
+				allWords[part] += allWords[word]
+			}
+		}
+		delete(allWords, word)
+	}
+
+	result := make(map[string][]string)
+	for _, category := range categoryNames {
+		result[category] = []string{}
+	}
+	for category, words := range categorizedWords {
+		for _, word := range words {
+			if !unknown[word] {
+				result[category] = append(result[category], word)
+				continue
+			}
+			for _, part := range replacements[word] {
+				result[part.category] = append(result[part.category], part.word)
+			}
+		}
+	}
+
+	Statusf("Split %d unknown hyphenated compounds into their parts\n", len(unknown))
+	return result, allWords
+}
@@ -0,0 +1,105 @@
+package classifier
+
+import (
+	"log"
+	"strings"
+
+	"github.com/jdkato/prose/v2"
+)
+
+// Full forms of the clitics prose splits off contractions ("do" + "n't").
+// "'s" only reaches this table when it isn't tagged as a possessive.
+var cliticExpansions = map[string]string{
+	"n't": "not",
+	"'re": "are",
+	"'ll": "will",
+	"'ve": "have",
+	"'m":  "am",
+	"'d":  "would",
+	"'s":  "is",
+}
+
+// Stems prose leaves in front of "n't" that aren't words on their own ("ca" + "n't")
+var negationStems = map[string]string{
+	"ca":  "can",
+	"wo":  "will",
+	"sha": "shall",
+	"ai":  "be",
+}
+
+// Contraction handling: expand, drop or none, from the Contractions config value
+var contractionMode = "expand"
+
+// Pick the contraction handling for the configured name
+func selectContractions(name string) string {
+	mode := strings.ToLower(name)
+	switch mode {
+	case "expand", "drop", "none":
+		return mode
+	case "":
+		return "none"
+	}
+	log.Printf("Unknown contractions mode '%s', falling back to 'none'\n", name)
+	return "none"
+}
+
+// Normalize typographic apostrophes so clitics match cliticExpansions
+func normalizeApostrophes(text string) string {
+	return strings.NewReplacer("’", "'", "ʼ", "'").Replace(text)
+}
+
+// Words token i of a document stands for once contractions are resolved:
+// the repaired stem of a negation ("ca" -> "can"), the full form of a clitic
+// under expand ("'re" -> "are"), or nothing for possessives and dropped
+// clitics. Contractions prose left whole ("she'd've") are split first.
+func contractionWords(tokens []prose.Token, i int) []string {
+	text := lowerWord(tokens[i].Text)
+	if contractionMode == "none" {
+		return []string{text}
+	}
+	text = normalizeApostrophes(text)
+
+	// Possessive markers never become words: "John 's", "boys '"
+	if tokens[i].Tag == "POS" {
+		return nil
+	}
+	if i+1 < len(tokens) && normalizeApostrophes(tokens[i+1].Text) == "n't" {
+		if stem, ok := negationStems[text]; ok {
+			return []string{stem}
+		}
+	}
+
+	stem, clitics := splitClitics(text)
+	var words []string
+	if stem != "" {
+		words = append(words, stem)
+	}
+	if contractionMode == "expand" {
+		for _, clitic := range clitics {
+			words = append(words, cliticExpansions[clitic])
+		}
+	}
+	return words
+}
+
+// Split trailing clitics off a token: "she'd've" -> "she", ["'d", "'ve"].
+// Tokens that are a single clitic return an empty stem.
+func splitClitics(text string) (string, []string) {
+	var clitics []string
+	for {
+		found := ""
+		for clitic := range cliticExpansions {
+			if strings.HasSuffix(text, clitic) && len(clitic) > len(found) {
+				found = clitic
+			}
+		}
+		if found == "" {
+			return text, clitics
+		}
+		text = strings.TrimSuffix(text, found)
+		clitics = append([]string{found}, clitics...)
+		if stem, ok := negationStems[text]; ok && found == "n't" {
+			text = stem
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package classifier
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jdkato/prose/v2"
+)
+
+// Most input sentences kept per word; MaxExampleSentences caps how many are written
+const maxCorpusSentencesPerWord = 20
+
+// Sentences from the input files in which each word occurred, collected across all files
+var corpusSentences = make(map[string][]string)
+
+// Split text into sentences with prose's segmenter, skipping tokenization and tagging
+func segmentSentences(content string) (sentences []string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("prose failed to segment text: %v", r)
+		}
+	}()
+
+	doc, err := prose.NewDocument(content,
+		prose.WithTokenization(false),
+		prose.WithTagging(false),
+		prose.WithExtraction(false))
+	if err != nil {
+		return nil, err
+	}
+	for _, sentence := range doc.Sentences() {
+		sentences = append(sentences, strings.Join(strings.Fields(sentence.Text), " "))
+	}
+	return sentences, nil
+}
+
+// Index of the sentence containing each token, or -1 if the token could not be
+// located. Tokens and sentences are found by searching the content in order.
+func tokenSentenceIndexes(content string, sentences []string, tokens []prose.Token) []int {
+	// Start offsets of the sentences whose first word could be found
+	type sentenceStart struct{ offset, index int }
+	var starts []sentenceStart
+	cursor := 0
+	for i, sentence := range sentences {
+		words := strings.Fields(sentence)
+		if len(words) == 0 {
+			continue
+		}
+		if offset := strings.Index(content[cursor:], words[0]); offset >= 0 {
+			starts = append(starts, sentenceStart{cursor + offset, i})
+			cursor += offset + len(words[0])
+		}
+	}
+
+	indexes := make([]int, len(tokens))
+	cursor = 0
+	next := 0
+	current := -1
+	for i, tok := range tokens {
+		indexes[i] = -1
+		offset := strings.Index(content[cursor:], tok.Text)
+		if offset < 0 {
+			continue
+		}
+		cursor += offset
+		for next < len(starts) && starts[next].offset <= cursor {
+			current = starts[next].index
+			next++
+		}
+		indexes[i] = current
+		cursor += len(tok.Text)
+	}
+	return indexes
+}
+
+// Remember a sentence for a word, skipping repeats and words that already have enough
+func recordCorpusSentence(sentences map[string][]string, word string, sentence string) {
+	if sentence == "" || len(sentences[word]) >= maxCorpusSentencesPerWord {
+		return
+	}
+	for _, existing := range sentences[word] {
+		if existing == sentence {
+			return
+		}
+	}
+	sentences[word] = append(sentences[word], sentence)
+}
+
+// Merge one file's sentences into the collection for all files
+func addCorpusSentences(sentences map[string][]string) {
+	for word, list := range sentences {
+		for _, sentence := range list {
+			recordCorpusSentence(corpusSentences, word, sentence)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package classifier
+
+import (
+	"bufio"
+	"fmt"
+)
+
+// Write Coverage.txt reporting how much of a reference word list appears in the corpus.
+// Returns the number of reference words.
+func writeCoverageReport(path string, referencePath string, corpusWords map[string]int) (int, error) {
+	referenceWords, err := loadWordList(referencePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read reference word list %s: %v", referencePath, err)
+	}
+
+	var covered, missing []string
+	for _, word := range referenceWords {
+		if corpusWords[word] > 0 {
+			covered = append(covered, word)
+		} else {
+			missing = append(missing, word)
+		}
+	}
+
+	percentage := 0.0
+	if len(referenceWords) > 0 {
+		percentage = float64(len(covered)) / float64(len(referenceWords)) * 100
+	}
+
+	file, err := createOutputFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	writer.WriteString(fmt.Sprintf("Reference list: %s\n", referencePath))
+	writer.WriteString(fmt.Sprintf("Coverage: %d of %d reference words (%.1f%%)\n", len(covered), len(referenceWords), percentage))
+
+	writer.WriteString(fmt.Sprintf("Covered words (%d)\n", len(covered)))
+	for _, word := range covered {
+		writer.WriteString(fmt.Sprintf("%s%s (%d)\n", indent(1), capitalizePhrase(word), corpusWords[word]))
+	}
+	writer.WriteString(fmt.Sprintf("Missing words (%d)\n", len(missing)))
+	for _, word := range missing {
+		writer.WriteString(indent(1) + capitalizePhrase(word) + "\n")
+	}
+	return len(referenceWords), writer.Flush()
+}
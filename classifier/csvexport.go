@@ -0,0 +1,48 @@
+package classifier
+
+import (
+	"encoding/csv"
+	"strconv"
+	"strings"
+)
+
+// Write Words.csv with one row per definition of each known word, categories in
+// canonical order and words by frequency. Returns the number of words written.
+func writeWordsCSV(path string, wordsByCategory map[string][]string, frequencies map[string]int) (int, error) {
+	file, err := createOutputFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"word", "category", "partOfSpeech", "definition", "example", "synonyms", "antonyms", "frequency"})
+
+	wordCount := 0
+	for _, category := range categoryNames {
+		for _, word := range wordsByCategory[category] {
+			word = lowerWord(word)
+			cachedData, exists := wordCache[word]
+			if !exists || len(cachedData.Definitions) == 0 {
+				continue
+			}
+			wordCount++
+			frequency := strconv.Itoa(frequencies[word])
+			for _, def := range cachedData.Definitions {
+				writer.Write([]string{
+					capitalizePhrase(word),
+					category,
+					def.PartOfSpeech,
+					def.Definition,
+					exampleOf(def),
+					strings.Join(def.Synonyms, "; "),
+					strings.Join(def.Antonyms, "; "),
+					frequency,
+				})
+			}
+		}
+	}
+
+	writer.Flush()
+	return wordCount, writer.Error()
+}
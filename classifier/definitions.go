@@ -0,0 +1,116 @@
+package classifier
+
+import (
+	"log"
+	"strings"
+)
+
+// Token overlap (Jaccard index) between two definitions after normalization
+func definitionSimilarity(a string, b string) float64 {
+	tokensA := strings.Fields(normalizeDefinition(a))
+	tokensB := strings.Fields(normalizeDefinition(b))
+	if len(tokensA) == 0 || len(tokensB) == 0 {
+		return 0
+	}
+
+	setA := make(map[string]bool)
+	for _, token := range tokensA {
+		setA[token] = true
+	}
+	setB := make(map[string]bool)
+	for _, token := range tokensB {
+		setB[token] = true
+	}
+
+	shared := 0
+	for token := range setA {
+		if setB[token] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(setA)+len(setB)-shared)
+}
+
+// Collapse definitions of the same part of speech whose wording overlaps at least
+// threshold, keeping the longest one and carrying over examples and synonyms
+func mergeSimilarDefinitions(definitions []Definition, threshold float64) []Definition {
+	var merged []Definition
+	for _, def := range definitions {
+		match := -1
+		for i, kept := range merged {
+			if kept.PartOfSpeech == def.PartOfSpeech && definitionSimilarity(kept.Definition, def.Definition) >= threshold {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			merged = append(merged, def)
+			continue
+		}
+
+		kept := merged[match]
+		if len(def.Definition) > len(kept.Definition) {
+			kept.Definition = def.Definition
+			kept.Source = def.Source
+		}
+		if kept.Example == "" {
+			kept.Example = def.Example
+		}
+		kept.Synonyms = deduplicateStrings(append(kept.Synonyms, def.Synonyms...))
+		kept.Antonyms = deduplicateStrings(append(kept.Antonyms, def.Antonyms...))
+		merged[match] = kept
+	}
+	return merged
+}
+
+// Check the duplicateDefinitions setting. An empty value keeps every definition,
+// as before the setting existed.
+func selectDuplicateDefinitions(name string) string {
+	mode := strings.ToLower(name)
+	switch mode {
+	case "remove", "merge", "keep":
+		return mode
+	case "":
+		return "keep"
+	}
+	log.Printf("Unknown duplicateDefinitions mode '%s', falling back to 'keep'\n", name)
+	return "keep"
+}
+
+// Drop definitions repeated within one entry according to DuplicateDefinitions:
+// "remove" drops those with the same part of speech, text and example (ignoring
+// case and punctuation), "merge" also folds definitions that only differ in their
+// examples into one, and "keep" leaves every definition the dictionary returned
+func deduplicateDefinitions(definitions []Definition, mode string) []Definition {
+	if mode != "remove" && mode != "merge" {
+		return definitions
+	}
+
+	var kept []Definition
+	index := make(map[string]int)
+	for _, def := range definitions {
+		key := def.PartOfSpeech + "\x00" + normalizeDefinition(def.Definition)
+		if mode == "remove" {
+			key += "\x00" + normalizeDefinition(def.Example)
+		}
+		i, seen := index[key]
+		if !seen {
+			index[key] = len(kept)
+			kept = append(kept, def)
+			continue
+		}
+
+		existing := kept[i]
+		if def.Example != "" && !strings.Contains(existing.Example, def.Example) {
+			if existing.Example == "" {
+				existing.Example = def.Example
+			} else {
+				existing.Example += "; " + def.Example
+			}
+		}
+		existing.Synonyms = deduplicateStrings(append(existing.Synonyms, def.Synonyms...))
+		existing.Antonyms = deduplicateStrings(append(existing.Antonyms, def.Antonyms...))
+		kept[i] = existing
+	}
+	return kept
+}
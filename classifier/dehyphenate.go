@@ -0,0 +1,57 @@
+package classifier
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Soft hyphen, which only marks where a word may be broken across lines
+const softHyphen = "\u00ad"
+
+// Append a line of input to the file content. With DehyphenateLineBreaks a word
+// split across lines with a hyphen ("exam-" then "ple") is joined back together,
+// unless the next line starts with a capital letter ("anti-" then "American").
+// Hyphenated compounds broken at their hyphen ("well-" then "known") are joined
+// too, since the line break alone can't tell them apart, but compounds with an
+// earlier hyphen ("mother-in-" then "law") keep theirs. A soft hyphen at the end
+// of a line always joins.
+func appendLine(content string, line string) string {
+	if config.DehyphenateLineBreaks {
+		trimmed := strings.TrimRight(content, " \t\r")
+		next := strings.TrimLeft(line, " \t")
+		switch {
+		case strings.HasSuffix(trimmed, softHyphen):
+			return strings.TrimSuffix(trimmed, softHyphen) + next + " "
+		case endsWithWordHyphen(trimmed) && startsWithLowercase(next):
+			if isHyphenatedCompound(trimmed) {
+				return trimmed + next + " "
+			}
+			return strings.TrimSuffix(trimmed, "-") + next + " "
+		}
+	}
+	return content + line + " "
+}
+
+// Whether the text ends in a letter followed by a single hyphen
+func endsWithWordHyphen(text string) bool {
+	if !strings.HasSuffix(text, "-") {
+		return false
+	}
+	last, _ := utf8.DecodeLastRuneInString(strings.TrimSuffix(text, "-"))
+	return unicode.IsLetter(last)
+}
+
+// Whether the last word of the text, before its trailing hyphen, already has a hyphen
+func isHyphenatedCompound(text string) bool {
+	word := strings.TrimSuffix(text, "-")
+	if space := strings.LastIndexAny(word, " \t"); space >= 0 {
+		word = word[space+1:]
+	}
+	return strings.Contains(word, "-")
+}
+
+func startsWithLowercase(text string) bool {
+	first, _ := utf8.DecodeRuneInString(text)
+	return unicode.IsLower(first)
+}
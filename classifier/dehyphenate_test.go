@@ -0,0 +1,73 @@
+package classifier
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Join lines the way readInputText does
+func joinLines(lines ...string) string {
+	var content string
+	for _, line := range lines {
+		content = appendLine(content, line)
+	}
+	return content
+}
+
+func TestAppendLineDehyphenates(t *testing.T) {
+	saved := config
+	defer func() { config = saved }()
+	config.DehyphenateLineBreaks = true
+
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{"word split across lines", []string{"an exam-", "ple of it"}, "an example of it "},
+		{"indented continuation", []string{"an exam- ", "   ple"}, "an example "},
+		{"compound broken at its hyphen", []string{"a well-", "known fact"}, "a wellknown fact "},
+		{"compound with an earlier hyphen", []string{"my mother-in-", "law"}, "my mother-in-law "},
+		{"soft hyphen", []string{"an exam\u00ad", "ple"}, "an example "},
+		{"soft hyphen before a capital", []string{"Mont\u00ad", "Blanc"}, "MontBlanc "},
+		{"capitalized next line", []string{"the anti-", "American vote"}, "the anti- American vote "},
+		{"dash after a space", []string{"wait -", "now"}, "wait - now "},
+		{"number range", []string{"pages 10-", "twelve"}, "pages 10- twelve "},
+		{"no hyphen", []string{"one line", "two line"}, "one line two line "},
+		{"stray carriage return", []string{"an exam-\r", "ple"}, "an example "},
+	}
+	for _, test := range tests {
+		if got := joinLines(test.lines...); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestAppendLineKeepsHyphensWhenDisabled(t *testing.T) {
+	saved := config
+	defer func() { config = saved }()
+	config.DehyphenateLineBreaks = false
+
+	if got, want := joinLines("an exam-", "ple"), "an exam- ple "; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestReadInputTextDehyphenatesCRLF(t *testing.T) {
+	saved := config
+	defer func() { config = saved }()
+	config.DehyphenateLineBreaks = true
+
+	path := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(path, []byte("an exam-\r\nple of a well-\r\nknown\r\nline\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readInputText(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "an example of a wellknown line "; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+package classifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Tokenize every input file and report how many words each category would get and
+// how many of them the caches already cover, without dictionary requests or output
+// files. Dictionary categorization and force_include.txt are not applied.
+func dryRun(ctx context.Context, inputDir string, outputDir string) error {
+	inputFiles, err := listInputFiles(inputDir, outputDir)
+	if err != nil {
+		return err
+	}
+	if err := checkProseModels(); err != nil {
+		return fmt.Errorf("failed to initialize the prose NLP models: %v", err)
+	}
+	included, excluded := wordFilterSet(includeWordList), wordFilterSet(excludeWordList)
+
+	allCategorizedWords := make(map[string][]string)
+	allWordsDict := make(map[string]int)
+	skippedFiles := 0
+	for _, inputFile := range inputFiles {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		categorizedWords, fileWords, err := processFile(inputFile)
+		if err != nil {
+			Statusf("Warning: skipping file %s: %v\n", inputFile, err)
+			skippedFiles++
+			continue
+		}
+		for category, words := range categorizedWords {
+			allCategorizedWords[category] = append(allCategorizedWords[category], words...)
+		}
+		for word, count := range fileWords {
+			allWordsDict[word] += count
+		}
+	}
+
+	// Apply the filters that need no dictionary data
+	if len(excluded) > 0 {
+		allCategorizedWords, allWordsDict = removeExcludedWords(allCategorizedWords, allWordsDict, excluded)
+	}
+	if config.MinWordLength > 1 || config.MinFrequency > 1 {
+		allCategorizedWords, allWordsDict = filterByThresholds(allCategorizedWords, allWordsDict, included)
+	}
+	if config.ExclusiveCategories {
+		allCategorizedWords = assignPrimaryCategories(allCategorizedWords)
+	}
+	if config.MinRank > 0 || config.MaxRank > 0 {
+		allCategorizedWords, allWordsDict = filterByRank(allCategorizedWords, allWordsDict, includedWordsIn(allWordsDict, included))
+	}
+	if config.MaxWordsPerCategory > 0 {
+		allCategorizedWords, allWordsDict = capWordsPerCategory(allCategorizedWords, allWordsDict, includedWordsIn(allWordsDict, included))
+	}
+
+	tokens := 0
+	cached, unknown, missing, skipped := 0, 0, 0, 0
+	lookupWords := lookupWordSet(allCategorizedWords)
+	for word, count := range allWordsDict {
+		tokens += count
+		if !lookupWords[word] {
+			skipped++
+			continue
+		}
+		cacheMutex.Lock()
+		entry, exists := wordCache[lowerWord(word)]
+		unknownData, isUnknown := wordUnknown[lowerWord(word)]
+		cacheMutex.Unlock()
+		switch {
+		case exists && !isCacheEntryStale(entry):
+			cached++
+		case isUnknown && !isUnknownStale(unknownData):
+			unknown++
+		default:
+			missing++
+		}
+	}
+
+	var report strings.Builder
+	report.WriteString("\n===== Dry Run =====\n")
+	report.WriteString(fmt.Sprintf("Files: %d (%d skipped)\n", len(inputFiles), skippedFiles))
+	report.WriteString(fmt.Sprintf("Word tokens: %d\n", tokens))
+	report.WriteString(fmt.Sprintf("Unique words: %d\n", len(allWordsDict)))
+	for _, category := range categoryNames {
+		unique := len(deduplicateStrings(allCategorizedWords[category]))
+		report.WriteString(fmt.Sprintf("%s%s: %d\n", indent(1), category, unique))
+	}
+	if skipped > 0 {
+		report.WriteString(fmt.Sprintf("Listed without lookup: %d\n", skipped))
+	}
+	report.WriteString(fmt.Sprintf("Cache hits: %d\n", cached))
+	if queryConfig.QueryForUnknownWords {
+		report.WriteString(fmt.Sprintf("Known unknown words: %d (queried again, queryForUnknownWords is on)\n", unknown))
+	} else {
+		report.WriteString(fmt.Sprintf("Known unknown words: %d\n", unknown))
+	}
+	report.WriteString(fmt.Sprintf("Cache misses needing lookup: %d\n", missing))
+	report.WriteString("No dictionary lookups were made and no output files were written.\n")
+
+	Statusf("%s", report.String())
+	return nil
+}
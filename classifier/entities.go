@@ -0,0 +1,91 @@
+package classifier
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+
+	"github.com/jdkato/prose/v2"
+)
+
+// Named entities collected across all files: label -> entity text -> count
+var namedEntities = make(map[string]map[string]int)
+
+// Headings for prose's entity labels, in the order Entities.txt lists them.
+// Labels not listed here follow under their own name.
+var entityLabels = []struct{ Label, Heading string }{
+	{"PERSON", "People"},
+	{"ORGANIZATION", "Organizations"},
+	{"GPE", "Locations"},
+}
+
+// Count the entities prose recognized in a document by label
+func findEntities(doc *prose.Document) map[string]map[string]int {
+	entities := make(map[string]map[string]int)
+	for _, entity := range doc.Entities() {
+		if entities[entity.Label] == nil {
+			entities[entity.Label] = make(map[string]int)
+		}
+		entities[entity.Label][entity.Text]++
+	}
+	return entities
+}
+
+// Add a file's entities to the totals when entity output is enabled
+func addEntities(entities map[string]map[string]int) {
+	if !config.GenerateEntities {
+		return
+	}
+	for label, texts := range entities {
+		if namedEntities[label] == nil {
+			namedEntities[label] = make(map[string]int)
+		}
+		for text, count := range texts {
+			namedEntities[label][text] += count
+		}
+	}
+}
+
+// Write the entities grouped by label, each group most frequent first with its
+// count. Returns the number of distinct entities.
+func writeEntities(path string, entities map[string]map[string]int) (int, error) {
+	file, err := createOutputFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	headings := make(map[string]string)
+	var labels []string
+	for _, entityLabel := range entityLabels {
+		headings[entityLabel.Label] = entityLabel.Heading
+		labels = append(labels, entityLabel.Label)
+	}
+	var otherLabels []string
+	for label := range entities {
+		if _, known := headings[label]; !known {
+			otherLabels = append(otherLabels, label)
+			headings[label] = label
+		}
+	}
+	sort.Strings(otherLabels)
+	labels = append(labels, otherLabels...)
+
+	writer := bufio.NewWriter(file)
+	listed := 0
+	for _, label := range labels {
+		texts := entities[label]
+		if len(texts) == 0 {
+			continue
+		}
+		if listed > 0 {
+			writer.WriteString("\n")
+		}
+		writer.WriteString(fmt.Sprintf("%s (%s):\n", headings[label], label))
+		for _, text := range sortByFrequency(texts) {
+			writer.WriteString(fmt.Sprintf("%s%s (%d)\n", indent(1), text, texts[text]))
+			listed++
+		}
+	}
+	return listed, writer.Flush()
+}
@@ -0,0 +1,124 @@
+package classifier
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// Read a word list file with one word or phrase per line, skipping blanks and # comments
+func loadWordList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, lowerWord(line))
+	}
+	return deduplicateStrings(words), scanner.Err()
+}
+
+// A force_include.txt word after the morphology transform, with the tag it got on its own
+type forcedWord struct {
+	word string
+	tag  string
+}
+
+// Read force_include.txt, tagging each word on its own so it can be placed in a category
+func loadForcedWords() []forcedWord {
+	if _, err := os.Stat(forceIncludePath); os.IsNotExist(err) {
+		return nil
+	}
+
+	words, err := loadWordList(forceIncludePath)
+	if err != nil {
+		log.Printf("Error reading %s: %v\n", forceIncludePath, err)
+		return nil
+	}
+
+	forced := make([]forcedWord, 0, len(words))
+	for _, word := range words {
+		tag := tagWord(word)
+		forced = append(forced, forcedWord{word: morphologyTransform(word, tag), tag: tag})
+	}
+	return forced
+}
+
+// The included words plus the forced words, which the length and frequency thresholds keep
+func withForcedWords(included map[string]bool, forced []forcedWord) map[string]bool {
+	if len(forced) == 0 {
+		return included
+	}
+	kept := make(map[string]bool)
+	for word := range included {
+		kept[word] = true
+	}
+	for _, forcedWord := range forced {
+		kept[forcedWord.word] = true
+	}
+	return kept
+}
+
+// Add the forced words to the collected words so they are always looked up and
+// written. Returns the forced words and those absent from the corpus.
+func addForcedWords(forcedWords []forcedWord, categorizedWords map[string][]string, allWords map[string]int) ([]string, []string) {
+	if forcedWords == nil {
+		return nil, nil
+	}
+
+	var forced, missing []string
+	for _, forcedWord := range forcedWords {
+		word := forcedWord.word
+		forced = append(forced, word)
+
+		if _, inCorpus := allWords[word]; inCorpus {
+			continue
+		}
+
+		missing = append(missing, word)
+		category := categoryForWord(word, forcedWord.tag)
+		if category == "" {
+			log.Printf("No category collects forced word '%s' (tag %s)\n", word, forcedWord.tag)
+			continue
+		}
+		allWords[word] = 0
+		categorizedWords[category] = append(categorizedWords[category], word)
+	}
+
+	Statusf("Force-included %d words (%d not in corpus)\n", len(forced), len(missing))
+	return forced, missing
+}
+
+// Write the forced words with their corpus frequency, noting those absent from the corpus
+func writeForcedWordsReport(path string, forced []string, missing []string, allWords map[string]int) error {
+	file, err := createOutputFile(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	isMissing := make(map[string]bool)
+	for _, word := range missing {
+		isMissing[word] = true
+	}
+
+	writer := bufio.NewWriter(file)
+	for _, word := range forced {
+		if isMissing[word] {
+			writer.WriteString(fmt.Sprintf("%s (forced, not in corpus)\n", capitalizePhrase(word)))
+		} else {
+			writer.WriteString(fmt.Sprintf("%s (forced, frequency %d)\n", capitalizePhrase(word), allWords[word]))
+		}
+	}
+	return writer.Flush()
+}
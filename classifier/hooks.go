@@ -0,0 +1,28 @@
+package classifier
+
+import (
+	"log"
+	"os/exec"
+	"strings"
+)
+
+// Run the configured post-run commands with the output directory as their last argument.
+// Commands are executed directly, without a shell, so the path is never interpreted.
+func runPostRunCommands(outputDir string) {
+	for _, command := range config.PostRunCommands {
+		if len(command) == 0 || command[0] == "" {
+			continue
+		}
+
+		args := append(append([]string{}, command[1:]...), outputDir)
+		Statusf("Running post-run command: %s %s\n", command[0], strings.Join(args, " "))
+
+		output, err := exec.Command(command[0], args...).CombinedOutput()
+		if len(output) > 0 {
+			log.Printf("Post-run command output:\n%s\n", output)
+		}
+		if err != nil {
+			Statusf("Post-run command %s failed: %v\n", command[0], err)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package classifier
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Matches dictionary definitions such as "simple past tense of run" or "comparative form of good"
+var inflectionPattern = regexp.MustCompile(
+	`(?i)^(?:\(.*?\)\s*)?((?:simple )?past tense(?: and past participle)?|past participle|present participle|` +
+		`plural|third-person singular(?: simple present indicative)?|comparative|superlative)` +
+		`(?: form)? of ([a-z][a-z' -]*?)[.;,]?\s*$`)
+
+// Find the base word when the dictionary describes the entry as an inflected form
+func detectBaseForm(word string, definitions []Definition) (string, string) {
+	for _, def := range definitions {
+		matches := inflectionPattern.FindStringSubmatch(strings.TrimSpace(def.Definition))
+		if matches == nil {
+			continue
+		}
+		base := strings.ToLower(strings.TrimSpace(matches[2]))
+		if base != "" && base != word {
+			return base, strings.ToLower(matches[1])
+		}
+	}
+	return "", ""
+}
+
+// Base form recorded for a cached word, detecting it for entries cached before it was stored
+func baseFormOf(word string, cachedData WordCache) (string, string) {
+	if cachedData.BaseForm != "" {
+		return cachedData.BaseForm, cachedData.InflectionType
+	}
+	return detectBaseForm(word, cachedData.Definitions)
+}
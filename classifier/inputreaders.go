@@ -0,0 +1,159 @@
+package classifier
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// An InputReader extracts the plain text of an input file
+type InputReader func(path string) (string, error)
+
+// Readers for the supported input file extensions, keyed by lowercase extension
+var inputReaders = map[string]InputReader{
+	".txt":      readTextInput,
+	".pdf":      readPDFInput,
+	".md":       readMarkdownInput,
+	".markdown": readMarkdownInput,
+	".html":     readHTMLInput,
+	".htm":      readHTMLInput,
+}
+
+// Extensions read when inputExtensions isn't configured
+var defaultInputExtensions = []string{".txt", ".pdf"}
+
+// Extensions enabled by inputExtensions, normalized to lowercase with a leading dot
+func enabledInputExtensions() []string {
+	if len(config.InputExtensions) == 0 {
+		return defaultInputExtensions
+	}
+	var extensions []string
+	for _, ext := range config.InputExtensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		extensions = append(extensions, ext)
+	}
+	return extensions
+}
+
+// Check that every enabled extension has a reader
+func checkInputExtensions() error {
+	for _, ext := range enabledInputExtensions() {
+		if _, ok := inputReaders[ext]; !ok {
+			var supported []string
+			for known := range inputReaders {
+				supported = append(supported, known)
+			}
+			sort.Strings(supported)
+			return fmt.Errorf("no reader for input extension %s (supported: %s)", ext, strings.Join(supported, ", "))
+		}
+	}
+	return nil
+}
+
+// Reader for a file based on its extension; false if the file type isn't supported
+// or not enabled
+func inputReaderFor(path string) (InputReader, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, enabled := range enabledInputExtensions() {
+		if ext == enabled {
+			reader, ok := inputReaders[ext]
+			return reader, ok
+		}
+	}
+	return nil, false
+}
+
+// Read an input file with the reader for its extension and join its lines into
+// one text for tokenization
+func readInputText(path string) (string, error) {
+	reader, ok := inputReaderFor(path)
+	if !ok {
+		return "", fmt.Errorf("unsupported input file type %s", filepath.Ext(path))
+	}
+	text, err := reader(path)
+	if err != nil {
+		return "", err
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	var content string
+	for scanner.Scan() {
+		content = appendLine(content, scanner.Text())
+	}
+	return content, scanner.Err()
+}
+
+func readTextInput(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Extract the text of every page of a PDF. Scanned PDFs without a text layer
+// yield no text.
+func readPDFInput(path string) (text string, err error) {
+	// The PDF parser panics on some malformed files
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to parse PDF: %v", r)
+		}
+	}()
+
+	file, reader, err := pdf.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open PDF: %v", err)
+	}
+	defer file.Close()
+
+	plainText, err := reader.GetPlainText()
+	if err != nil {
+		return "", fmt.Errorf("failed to extract PDF text: %v", err)
+	}
+	var buffer bytes.Buffer
+	if _, err := buffer.ReadFrom(plainText); err != nil {
+		return "", fmt.Errorf("failed to extract PDF text: %v", err)
+	}
+	return buffer.String(), nil
+}
+
+// Find the supported input files in the input directory and its subdirectories,
+// in lexical order. Hidden directories and the output directory are skipped so
+// generated files aren't read back as input.
+func walkInputFiles(inputDir string, outputDir string) ([]string, error) {
+	outputAbs, _ := filepath.Abs(outputDir)
+	var inputFiles []string
+	err := filepath.WalkDir(inputDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path == inputDir {
+				return nil
+			}
+			if strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if abs, _ := filepath.Abs(path); abs == outputAbs {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if _, supported := inputReaderFor(entry.Name()); supported {
+			inputFiles = append(inputFiles, path)
+		}
+		return nil
+	})
+	return inputFiles, err
+}
@@ -0,0 +1,105 @@
+package classifier
+
+import (
+	"encoding/json"
+)
+
+// Layout of results.json. Every list is written as [] when empty, never null.
+type jsonResults struct {
+	Categories   []jsonCategory   `json:"categories"`
+	UnknownWords []string         `json:"unknownWords"`
+	Totals       jsonResultTotals `json:"totals"`
+}
+
+type jsonCategory struct {
+	Name  string     `json:"name"`
+	Words []jsonWord `json:"words"`
+}
+
+type jsonWord struct {
+	Word        string           `json:"word"`
+	Frequency   int              `json:"frequency"`
+	Phonetic    string           `json:"phonetic"`
+	Origin      string           `json:"origin"`
+	Definitions []jsonDefinition `json:"definitions"`
+	Synonyms    []string         `json:"synonyms"`
+	Antonyms    []string         `json:"antonyms"`
+}
+
+type jsonDefinition struct {
+	PartOfSpeech string   `json:"partOfSpeech"`
+	Definition   string   `json:"definition"`
+	Example      string   `json:"example"`
+	Synonyms     []string `json:"synonyms"`
+	Antonyms     []string `json:"antonyms"`
+}
+
+type jsonResultTotals struct {
+	Files        int `json:"files"`
+	UniqueWords  int `json:"uniqueWords"`
+	KnownWords   int `json:"knownWords"`
+	UnknownWords int `json:"unknownWords"`
+}
+
+// A copy of the list that encodes as [] when empty
+func nonNilStrings(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+// Write results.json with every category's known words and their dictionary
+// details, the unknown words and run totals. Returns the number of known words.
+func writeJSONResults(path string, wordsByCategory map[string][]string, frequencies map[string]int, unknownWords []string, files int) (int, error) {
+	results := jsonResults{
+		Categories:   []jsonCategory{},
+		UnknownWords: nonNilStrings(unknownWords),
+	}
+
+	known := make(map[string]bool)
+	for _, category := range categoryNames {
+		entry := jsonCategory{Name: category, Words: []jsonWord{}}
+		for _, word := range wordsByCategory[category] {
+			word = lowerWord(word)
+			cachedData, exists := wordCache[word]
+			if !exists {
+				continue
+			}
+			known[word] = true
+			item := jsonWord{
+				Word:        word,
+				Frequency:   frequencies[word],
+				Phonetic:    cachedData.Phonetic,
+				Origin:      cachedData.Origin,
+				Definitions: []jsonDefinition{},
+				Synonyms:    nonNilStrings(cachedData.Synonyms),
+				Antonyms:    nonNilStrings(cachedData.Antonyms),
+			}
+			for _, def := range cachedData.Definitions {
+				item.Definitions = append(item.Definitions, jsonDefinition{
+					PartOfSpeech: def.PartOfSpeech,
+					Definition:   def.Definition,
+					Example:      exampleOf(def),
+					Synonyms:     nonNilStrings(def.Synonyms),
+					Antonyms:     nonNilStrings(def.Antonyms),
+				})
+			}
+			entry.Words = append(entry.Words, item)
+		}
+		results.Categories = append(results.Categories, entry)
+	}
+
+	results.Totals = jsonResultTotals{
+		Files:        files,
+		UniqueWords:  len(frequencies),
+		KnownWords:   len(known),
+		UnknownWords: len(unknownWords),
+	}
+
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	return len(known), writeOutputFile(path, append(data, '\n'))
+}
@@ -0,0 +1,88 @@
+package classifier
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Scripts of the dictionaryapi.dev languages not written in Latin letters
+var languageScripts = map[string][]string{
+	"ar": {"Arabic"},
+	"hi": {"Devanagari"},
+	"ja": {"Han", "Hiragana", "Katakana"},
+	"ko": {"Hangul"},
+	"ru": {"Cyrillic"},
+}
+
+var (
+	wordScripts []*unicode.RangeTable // Scripts a word's letters may come from
+	scriptsKey  string                // Names of wordScripts and the other characters words may hold, part of the token cache key
+)
+
+// Language code sent to dictionaryapi.dev, "en" unless configured
+func dictionaryLanguage() string {
+	if queryConfig.Language == "" {
+		return "en"
+	}
+	return queryConfig.Language
+}
+
+// Apply the configured language: pick the scripts words may be written in and give
+// every non-English language its own word cache. Scripts come from outputConfig.yml,
+// or else from the language (Latin, which includes accented letters, by default).
+func setupLanguage() error {
+	language := dictionaryLanguage()
+
+	names := config.Scripts
+	if len(names) == 0 {
+		names = languageScripts[strings.ToLower(language)]
+	}
+	if len(names) == 0 {
+		names = []string{"Latin"}
+	}
+	wordScripts = nil
+	for _, name := range names {
+		table, ok := unicode.Scripts[name]
+		if !ok {
+			return fmt.Errorf("unknown Unicode script %q", name)
+		}
+		wordScripts = append(wordScripts, table)
+	}
+	scriptsKey = fmt.Sprintf("%s;%t;%t;%s", strings.Join(names, ","), config.AllowApostrophes, config.AllowDigits, config.ExtraWordCharacters)
+
+	if language != "en" {
+		cachePath = fmt.Sprintf("word_cache_%s.json", language)
+		unknownPath = fmt.Sprintf("word_unknown_%s.json", language)
+		// prose only has an English model, so its tags (and the morphology rules) are
+		// unreliable here and many words end up in OtherWords
+		Statusf("Language %s: part-of-speech tagging is English-only, words may be categorized as OtherWords "+
+			"(categorizeBy: dictionary uses the dictionary's parts of speech instead)\n", language)
+	}
+	return nil
+}
+
+// Whether text consists only of letters from the configured scripts. Apostrophes
+// inside a word, digits and extraWordCharacters are accepted when configured, as
+// long as the word also has a letter.
+func isWordText(text string) bool {
+	runes := []rune(text)
+	hasLetter, others := false, 0
+	for i, r := range runes {
+		switch {
+		case unicode.IsLetter(r):
+			if !unicode.In(r, wordScripts...) {
+				return false
+			}
+			hasLetter = true
+			continue
+		case config.AllowApostrophes && (r == '\'' || r == '’') && i > 0 && i < len(runes)-1:
+		case config.AllowDigits && unicode.IsDigit(r):
+		case strings.ContainsRune(config.ExtraWordCharacters, r):
+		default:
+			return false
+		}
+		others++
+	}
+	return hasLetter || others == 0
+}
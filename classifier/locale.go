@@ -0,0 +1,35 @@
+package classifier
+
+import (
+	"log"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// Language whose casing rules are used to lowercase words, e.g. "tr" for the
+// Turkish dotted and dotless i. Undetermined keeps strings.ToLower's behavior.
+var localeTag = language.Und
+
+// Parse the configured locale, falling back to language-neutral casing
+func selectLocale(name string) language.Tag {
+	if name == "" {
+		return language.Und
+	}
+	tag, err := language.Parse(name)
+	if err != nil {
+		log.Printf("Unknown locale '%s', falling back to language-neutral lowercasing: %v\n", name, err)
+		return language.Und
+	}
+	return tag
+}
+
+// Lowercase a word using the configured locale. A new Caser is built on every
+// call because Casers keep state and cannot be shared between goroutines.
+func lowerWord(word string) string {
+	if localeTag == language.Und {
+		return strings.ToLower(word)
+	}
+	return cases.Lower(localeTag).String(word)
+}
@@ -0,0 +1,64 @@
+package classifier
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// Console receives the status messages and progress lines shown while running.
+// The statusOutput setting decides whether status messages also go to the log.
+var Console io.Writer = os.Stdout
+
+// Where status messages are written: to the log and the console, or just one of them
+func statusTargets() (toLog bool, toConsole bool) {
+	switch strings.ToLower(config.StatusOutput) {
+	case "log":
+		return true, false
+	case "console":
+		return false, true
+	}
+	return true, true
+}
+
+// Check the statusOutput setting, falling back to writing both
+func checkStatusOutput() {
+	switch strings.ToLower(config.StatusOutput) {
+	case "", "both", "log", "console":
+		return
+	}
+	log.Printf("Unknown statusOutput '%s', falling back to 'both'\n", config.StatusOutput)
+	config.StatusOutput = "both"
+}
+
+// Statusf writes a status message to the log and the console as configured by
+// statusOutput
+func Statusf(format string, args ...interface{}) {
+	writeStatus(fmt.Sprintf(format, args...))
+}
+
+// Statusln is Statusf with fmt.Println formatting
+func Statusln(args ...interface{}) {
+	writeStatus(fmt.Sprintln(args...))
+}
+
+// Leading newlines only end the console's progress line, so they are left out of the log
+func writeStatus(message string) {
+	toLog, toConsole := statusTargets()
+	if toLog {
+		log.Print(strings.TrimLeft(message, "\n"))
+	}
+	if toConsole {
+		io.WriteString(Console, message)
+	}
+}
+
+// Write progress and summaries that only belong on the console, unless status
+// messages are limited to the log
+func consolef(format string, args ...interface{}) {
+	if _, toConsole := statusTargets(); toConsole {
+		fmt.Fprintf(Console, format, args...)
+	}
+}
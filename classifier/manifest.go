@@ -0,0 +1,60 @@
+package classifier
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ManifestEntry describes a single generated output file
+type ManifestEntry struct {
+	File      string `json:"file"`
+	Category  string `json:"category"`
+	Format    string `json:"format"`
+	WordCount int    `json:"wordCount"`
+	Bytes     int64  `json:"bytes"`
+}
+
+// Manifest lists every file generated by a run
+type Manifest struct {
+	GeneratedAt time.Time       `json:"generatedAt"`
+	Files       []ManifestEntry `json:"files"`
+}
+
+// Output files generated during the current run
+var manifestEntries []ManifestEntry
+
+// Record a generated output file for the manifest
+func recordOutputFile(path string, category string, format string, wordCount int) {
+	manifestEntries = append(manifestEntries, ManifestEntry{
+		File:      path,
+		Category:  category,
+		Format:    format,
+		WordCount: wordCount,
+	})
+}
+
+// Write manifest.json listing every recorded output file with its size
+func writeManifest(outputDir string) error {
+	manifest := Manifest{
+		GeneratedAt: time.Now(),
+		Files:       []ManifestEntry{},
+	}
+
+	for _, entry := range manifestEntries {
+		if info, err := os.Stat(entry.File); err == nil {
+			entry.Bytes = info.Size()
+		}
+		if relPath, err := filepath.Rel(outputDir, entry.File); err == nil {
+			entry.File = filepath.ToSlash(relPath)
+		}
+		manifest.Files = append(manifest.Files, entry)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeOutputFile(filepath.Join(outputDir, "manifest.json"), data)
+}
@@ -0,0 +1,125 @@
+package classifier
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	urlPattern = regexp.MustCompile(`(?i)\b(?:https?://|ftp://|www\.|mailto:)[^\s<>()"']*[^\s<>()"'.,;:!?]`)
+
+	markdownFence         = regexp.MustCompile("^\\s*(```|~~~)")
+	markdownInlineCode    = regexp.MustCompile("`[^`]*`")
+	markdownImage         = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink          = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownReferenceLink = regexp.MustCompile(`\[([^\]]*)\]\[[^\]]*\]`)
+	markdownReferenceDef  = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s*\S+`)
+	markdownAutolink      = regexp.MustCompile(`<(?:https?|ftp|mailto):[^>]*>`)
+	markdownLinePrefix    = regexp.MustCompile(`^\s{0,3}(?:#{1,6}\s+|>\s?|[-*+]\s+|\d+[.)]\s+)+`)
+	markdownRule          = regexp.MustCompile(`^\s{0,3}(?:[-*_]\s*){3,}$`)
+	markdownEmphasis      = regexp.MustCompile(`\*{1,3}|_{2,3}|~~`)
+
+	htmlComment    = regexp.MustCompile(`(?s)<!--.*?-->`)
+	htmlCodeBlocks = regexp.MustCompile(`(?is)<(script|style|pre|code)\b[^>]*>.*?</(?:script|style|pre|code)\s*>`)
+	htmlBlockTag   = regexp.MustCompile(`(?i)</?(?:p|div|br|li|ul|ol|h[1-6]|tr|td|th|table|section|article|blockquote|header|footer)\b[^>]*>`)
+	htmlTag        = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// Remove URLs so their parts aren't counted as words
+func stripURLs(text string) string {
+	return urlPattern.ReplaceAllString(text, " ")
+}
+
+// Read a Markdown file as plain text: code blocks, inline code and URLs are
+// dropped, links and images keep their text, and formatting marks are removed
+func readMarkdownInput(path string) (string, error) {
+	text, err := readTextInput(path)
+	if err != nil {
+		return "", err
+	}
+	return stripMarkdown(text), nil
+}
+
+func stripMarkdown(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	// Skip YAML front matter
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if trimmed := strings.TrimSpace(lines[i]); trimmed == "---" || trimmed == "..." {
+				lines = lines[i+1:]
+				break
+			}
+		}
+	}
+
+	var result []string
+	inFence := false
+	fence := ""
+	previousBlank := true
+	inIndentedCode := false
+	for _, line := range lines {
+		if match := markdownFence.FindStringSubmatch(line); match != nil {
+			if !inFence {
+				inFence, fence = true, match[1]
+			} else if match[1] == fence {
+				inFence = false
+			}
+			result = append(result, "")
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		// Indented code blocks start after a blank line
+		blank := strings.TrimSpace(line) == ""
+		indented := strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")
+		if indented && !blank && (previousBlank || inIndentedCode) {
+			inIndentedCode = true
+			continue
+		}
+		if !blank {
+			inIndentedCode = false
+		}
+		previousBlank = blank
+
+		if markdownRule.MatchString(line) || markdownReferenceDef.MatchString(line) {
+			result = append(result, "")
+			continue
+		}
+
+		line = markdownInlineCode.ReplaceAllString(line, " ")
+		line = markdownImage.ReplaceAllString(line, "$1")
+		line = markdownLink.ReplaceAllString(line, "$1")
+		line = markdownReferenceLink.ReplaceAllString(line, "$1")
+		line = markdownAutolink.ReplaceAllString(line, " ")
+		line = stripURLs(line)
+		line = htmlTag.ReplaceAllString(line, " ")
+		line = markdownLinePrefix.ReplaceAllString(line, "")
+		line = markdownEmphasis.ReplaceAllString(line, "")
+		line = strings.ReplaceAll(line, "|", " ")
+		result = append(result, html.UnescapeString(line))
+	}
+	return strings.Join(result, "\n")
+}
+
+// Read an HTML file as plain text: comments, scripts, styles, code, tags and URLs
+// are dropped and entities decoded
+func readHTMLInput(path string) (string, error) {
+	text, err := readTextInput(path)
+	if err != nil {
+		return "", err
+	}
+	return stripHTMLDocument(text), nil
+}
+
+func stripHTMLDocument(text string) string {
+	text = htmlComment.ReplaceAllString(text, " ")
+	text = htmlCodeBlocks.ReplaceAllString(text, " ")
+	text = htmlBlockTag.ReplaceAllString(text, "\n")
+	text = htmlTag.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	return stripURLs(text)
+}
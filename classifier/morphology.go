@@ -0,0 +1,271 @@
+package classifier
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/kljensen/snowball/english"
+)
+
+// MorphologyFunc reduces a word to the form used for counting and lookup.
+// The POS tag from prose is passed along so lemmatizers can disambiguate.
+type MorphologyFunc func(word string, tag string) string
+
+// Registered morphological transforms, keyed by the Morphology config value
+var morphologies = map[string]MorphologyFunc{
+	"none":  noMorphology,
+	"stem":  stemWord,
+	"lemma": lemmatizeWord,
+}
+
+// Active morphological transform, selected at startup
+var morphologyTransform MorphologyFunc = noMorphology
+
+// Pick the morphological transform for the configured name
+func selectMorphology(name string) MorphologyFunc {
+	if name == "" {
+		return noMorphology
+	}
+	transform, ok := morphologies[strings.ToLower(name)]
+	if !ok {
+		log.Printf("Unknown morphology '%s', falling back to 'none'\n", name)
+		return noMorphology
+	}
+	return transform
+}
+
+// Leave the word as it is
+func noMorphology(word string, tag string) string {
+	return word
+}
+
+// Reduce the word to its Snowball (Porter2) stem
+func stemWord(word string, tag string) string {
+	if strings.ContainsAny(word, " -") {
+		return word
+	}
+	return english.Stem(word, false)
+}
+
+// Irregular inflections that the suffix rules below cannot recover
+var irregularLemmas = map[string]string{
+	"am": "be", "is": "be", "are": "be", "was": "be", "were": "be", "been": "be", "being": "be",
+	"has": "have", "had": "have", "having": "have",
+	"does": "do", "did": "do", "done": "do",
+	"went": "go", "gone": "go", "goes": "go",
+	"ran": "run", "saw": "see", "seen": "see", "took": "take", "taken": "take",
+	"came": "come", "made": "make", "said": "say", "got": "get", "gotten": "get",
+	"knew": "know", "known": "know", "thought": "think", "told": "tell",
+	"found": "find", "gave": "give", "given": "give", "left": "leave",
+	"felt": "feel", "brought": "bring", "bought": "buy", "began": "begin", "begun": "begin",
+	"kept": "keep", "held": "hold", "wrote": "write", "written": "write",
+	"stood": "stand", "heard": "hear", "meant": "mean", "met": "meet",
+	"paid": "pay", "sat": "sit", "spoke": "speak", "spoken": "speak",
+	"led": "lead", "grew": "grow", "grown": "grow", "lost": "lose",
+	"fell": "fall", "fallen": "fall", "sent": "send", "built": "build",
+	"understood": "understand", "drew": "draw", "drawn": "draw",
+	"broke": "break", "broken": "break", "spent": "spend", "rose": "rise", "risen": "rise",
+	"drove": "drive", "driven": "drive", "ate": "eat", "eaten": "eat",
+	"chose": "choose", "chosen": "choose", "wore": "wear", "worn": "wear",
+	"children": "child", "men": "man", "women": "woman", "people": "person",
+	"feet": "foot", "teeth": "tooth", "mice": "mouse", "geese": "goose",
+	"better": "good", "best": "good", "worse": "bad", "worst": "bad",
+	"dying": "die", "lying": "lie", "tying": "tie", "aged": "age", "owed": "owe", "eyed": "eye",
+}
+
+// Reduce the word to a dictionary base form using its POS tag
+func lemmatizeWord(word string, tag string) string {
+	switch tag {
+	case "NNS", "NNPS", "VBD", "VBN", "VBG", "VBZ", "VBP", "JJR", "JJS", "RBR", "RBS":
+		if lemma, ok := irregularLemmas[word]; ok {
+			return lemma
+		}
+	}
+
+	switch tag {
+	case "NNS", "NNPS":
+		return singularize(word)
+	case "VBD", "VBN", "VBG", "VBZ":
+		return verbBase(word, tag)
+	case "JJR", "JJS", "RBR", "RBS":
+		return adjectiveBase(word, tag)
+	}
+	return word
+}
+
+func singularize(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "shes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "xes"),
+		strings.HasSuffix(word, "zes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"):
+		return word
+	case strings.HasSuffix(word, "s") && len(word) > 3:
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+func verbBase(word string, tag string) string {
+	switch tag {
+	case "VBZ":
+		return singularize(word)
+	case "VBG":
+		if strings.HasSuffix(word, "ing") && len(word) > 4 && hasVowel(word[:len(word)-3]) {
+			return restoreStem(word[:len(word)-3])
+		}
+	case "VBD", "VBN":
+		if strings.HasSuffix(word, "ied") && len(word) > 4 {
+			return word[:len(word)-3] + "y"
+		}
+		// "agreed" -> "agree", "freed" -> "free"
+		if strings.HasSuffix(word, "eed") && len(word) > 4 {
+			return word[:len(word)-1]
+		}
+		if strings.HasSuffix(word, "ed") && len(word) > 3 && hasVowel(word[:len(word)-2]) {
+			return restoreStem(word[:len(word)-2])
+		}
+	}
+	return word
+}
+
+func adjectiveBase(word string, tag string) string {
+	suffix := "er"
+	if tag == "JJS" || tag == "RBS" {
+		suffix = "est"
+	}
+	if !strings.HasSuffix(word, suffix) || len(word) <= len(suffix)+2 {
+		return word
+	}
+	stem := word[:len(word)-len(suffix)]
+	if strings.HasSuffix(stem, "i") {
+		return stem[:len(stem)-1] + "y"
+	}
+	return restoreStem(stem)
+}
+
+// Endings no base word has, left when a final "e" was dropped ("larger", "judged", "forced")
+var droppedEEndings = []string{"v", "dg", "rg", "rc", "nc", "rs", "ns"}
+
+// Undo consonant doubling ("running" -> "run") or restore a dropped "e" ("making" -> "make")
+func restoreStem(stem string) string {
+	n := len(stem)
+	// Only a consonant-vowel-consonant ending was doubled ("stopp" -> "stop"), so
+	// "added", "stuffed" and "buzzed" keep their double letters. A doubled "l" is
+	// undone only after an earlier syllable ("controll", "travell"), not in "called".
+	if n >= 4 && stem[n-1] == stem[n-2] && !isVowel(stem[n-1]) && isVowel(stem[n-3]) && !isVowel(stem[n-4]) {
+		switch {
+		case stem[n-1] == 'l':
+			if strings.IndexByte("eo", stem[n-3]) >= 0 && hasVowel(stem[:n-3]) {
+				return stem[:n-1]
+			}
+		case !strings.ContainsRune("fsz", rune(stem[n-1])):
+			return stem[:n-1]
+		}
+		return stem
+	}
+
+	if needsDroppedE(stem) {
+		return stem + "e"
+	}
+	return stem
+}
+
+// Whether a stem lost its final "e" to the suffix
+func needsDroppedE(stem string) bool {
+	n := len(stem)
+	if n < 2 {
+		return false
+	}
+	last, beforeLast := stem[n-1], stem[n-2]
+	switch {
+	// "continued", "argued"
+	case last == 'u':
+		return true
+	// "simpler", "settled", but not "called" or "hurled"
+	case last == 'l' && !isVowel(beforeLast) && !strings.ContainsRune("lrwy", rune(beforeLast)):
+		return true
+	// "used", "closed", "amazed", but not "focused" or "biased"
+	case (last == 's' || last == 'z') && isVowel(beforeLast):
+		return !strings.HasSuffix(stem, "cus") && !strings.HasSuffix(stem, "ias")
+	}
+	for _, ending := range droppedEEndings {
+		if strings.HasSuffix(stem, ending) {
+			return true
+		}
+	}
+
+	// Short consonant-vowel-consonant stems ("hoped", "stated"), but not those starting
+	// with a vowel ("edited", "exited")
+	return n >= 3 && n <= 4 && !isVowel(last) && isVowel(beforeLast) && beforeLast != 'e' &&
+		!isVowel(stem[n-3]) && (n == 3 || !isVowel(stem[0])) && !strings.ContainsRune("wxy", rune(last))
+}
+
+// Whether the text has a vowel, so "shed" or "bled" aren't read as "sh" + "-ed"
+func hasVowel(text string) bool {
+	return strings.ContainsAny(text, "aeiouy")
+}
+
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiou", b) >= 0
+}
+
+// Surface forms seen for each reduced word across all files, with their counts
+var surfaceForms = make(map[string]map[string]int)
+
+// Remember that the morphological transform reduced surface to word
+func recordSurfaceForm(forms map[string]map[string]int, word string, surface string) {
+	if word == surface {
+		return
+	}
+	if forms[word] == nil {
+		forms[word] = make(map[string]int)
+	}
+	forms[word][surface]++
+}
+
+// Add a file's surface forms to the totals when they are reported
+func addSurfaceForms(forms map[string]map[string]int) {
+	if !config.GenerateSurfaceForms {
+		return
+	}
+	for word, counts := range forms {
+		for surface, count := range counts {
+			if surfaceForms[word] == nil {
+				surfaceForms[word] = make(map[string]int)
+			}
+			surfaceForms[word][surface] += count
+		}
+	}
+}
+
+// Write each reduced word, most frequent first, with the surface forms it merged,
+// e.g. "Run: ran (2), running (1)". Returns the number of words listed.
+func writeSurfaceForms(path string, frequencies map[string]int) (int, error) {
+	file, err := createOutputFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	listed := 0
+	for _, word := range sortByFrequency(frequencies) {
+		forms, ok := surfaceForms[word]
+		if !ok {
+			continue
+		}
+		var parts []string
+		for _, surface := range sortByFrequency(forms) {
+			parts = append(parts, fmt.Sprintf("%s (%d)", surface, forms[surface]))
+		}
+		writer.WriteString(fmt.Sprintf("%s: %s\n", capitalizePhrase(word), strings.Join(parts, ", ")))
+		listed++
+	}
+	return listed, writer.Flush()
+}
@@ -0,0 +1,88 @@
+package classifier
+
+import "testing"
+
+func TestLemmatizeWord(t *testing.T) {
+	tests := []struct {
+		word string
+		tag  string
+		want string
+	}{
+		// Doubled consonants after a consonant-vowel-consonant stem are undone
+		{"running", "VBG", "run"},
+		{"stopped", "VBD", "stop"},
+		{"beginning", "VBG", "begin"},
+		{"committed", "VBN", "commit"},
+		{"bigger", "JJR", "big"},
+
+		// Words whose base form ends in a double letter keep it
+		{"added", "VBD", "add"},
+		{"stuffed", "VBD", "stuff"},
+		{"buzzed", "VBD", "buzz"},
+		{"called", "VBD", "call"},
+		{"missing", "VBG", "miss"},
+		{"needed", "VBD", "need"},
+
+		// Doubled "l" after an earlier syllable, but not in one-syllable words
+		{"controlled", "VBD", "control"},
+		{"travelling", "VBG", "travel"},
+		{"filled", "VBD", "fill"},
+		{"installed", "VBD", "install"},
+		{"taller", "JJR", "tall"},
+
+		// A dropped "e" is restored
+		{"hoped", "VBD", "hope"},
+		{"making", "VBG", "make"},
+		{"stated", "VBD", "state"},
+		{"larger", "JJR", "large"},
+		{"simpler", "JJR", "simple"},
+		{"nicer", "JJR", "nice"},
+		{"judged", "VBD", "judge"},
+		{"forced", "VBD", "force"},
+		{"lived", "VBD", "live"},
+		{"settled", "VBD", "settle"},
+		{"continued", "VBD", "continue"},
+		{"closed", "VBD", "close"},
+		{"amazed", "VBD", "amaze"},
+
+		// Stems that never had an "e"
+		{"edited", "VBD", "edit"},
+		{"exited", "VBD", "exit"},
+		{"opened", "VBD", "open"},
+		{"waited", "VBD", "wait"},
+		{"focused", "VBD", "focus"},
+		{"longer", "JJR", "long"},
+		{"called", "VBD", "call"},
+		{"walked", "VBD", "walk"},
+		{"wanted", "VBD", "want"},
+
+		// "-ee" stems
+		{"agreed", "VBD", "agree"},
+		{"freed", "VBN", "free"},
+		{"agreeing", "VBG", "agree"},
+
+		// Short forms
+		{"used", "VBD", "use"},
+		{"using", "VBG", "use"},
+		{"doing", "VBG", "do"},
+		{"shed", "VBD", "shed"},
+		{"bled", "VBD", "bled"},
+
+		// Other suffixes and irregular forms
+		{"tried", "VBD", "try"},
+		{"happier", "JJR", "happy"},
+		{"foxes", "NNS", "fox"},
+		{"cities", "NNS", "city"},
+		{"went", "VBD", "go"},
+		{"children", "NNS", "child"},
+
+		// Base tags are left alone
+		{"added", "NN", "added"},
+		{"series", "NN", "series"},
+	}
+	for _, test := range tests {
+		if got := lemmatizeWord(test.word, test.tag); got != test.want {
+			t.Errorf("lemmatizeWord(%q, %s) = %q, want %q", test.word, test.tag, got, test.want)
+		}
+	}
+}
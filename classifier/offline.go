@@ -0,0 +1,175 @@
+package classifier
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// OfflineProvider looks words up in a local dictionary instead of over HTTP. The
+// offlineDictionary path is either a JSON lexicon in the word_cache.json format or
+// a WordNet database directory containing data.noun, data.verb, data.adj and data.adv.
+type OfflineProvider struct{}
+
+// Entries of the loaded local dictionary
+var offlineEntries map[string]WordCache
+
+func (OfflineProvider) Lookup(ctx context.Context, word string) (WordCache, error) {
+	if offlineEntries == nil {
+		return WordCache{}, fmt.Errorf("offline dictionary is not loaded")
+	}
+	return offlineEntries[word], nil
+}
+
+// Whether lookups need the local dictionary loaded
+func usesOfflineDictionary() bool {
+	if queryConfig.OfflineMode {
+		return true
+	}
+	for _, name := range queryConfig.Sources {
+		if name == "offline" {
+			return true
+		}
+	}
+	return false
+}
+
+// Load the local dictionary from a JSON lexicon file or a WordNet directory
+func loadOfflineDictionary(path string) (map[string]WordCache, error) {
+	if path == "" {
+		return nil, fmt.Errorf("offlineDictionary is not set")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return loadWordNet(path)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lexicon, _, _, err := decodeWordCache(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a JSON lexicon: %v", path, err)
+	}
+	entries := make(map[string]WordCache, len(lexicon))
+	for word, entry := range lexicon {
+		entries[lowerWord(word)] = entry
+	}
+	return entries, nil
+}
+
+// WordNet data files and the part of speech of their synsets
+var wordNetFiles = []struct {
+	Name         string
+	PartOfSpeech string
+}{
+	{"data.noun", "noun"},
+	{"data.verb", "verb"},
+	{"data.adj", "adjective"},
+	{"data.adv", "adverb"},
+}
+
+// Read the synsets of a WordNet database. Each synset becomes a definition of every
+// word in it, with the synset's other words as synonyms.
+func loadWordNet(dir string) (map[string]WordCache, error) {
+	entries := make(map[string]WordCache)
+	found := false
+	for _, dataFile := range wordNetFiles {
+		file, err := os.Open(filepath.Join(dir, dataFile.Name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			words, def, ok := parseWordNetSynset(scanner.Text(), dataFile.PartOfSpeech)
+			if !ok {
+				continue
+			}
+			for _, word := range words {
+				entry := entries[word]
+				wordDef := def
+				wordDef.Synonyms = []string{}
+				for _, other := range words {
+					if other != word {
+						wordDef.Synonyms = append(wordDef.Synonyms, other)
+					}
+				}
+				entry.Definitions = append(entry.Definitions, wordDef)
+				entry.Synonyms = deduplicateStrings(append(entry.Synonyms, wordDef.Synonyms...))
+				if entry.Antonyms == nil {
+					entry.Antonyms = []string{}
+				}
+				entries[word] = entry
+			}
+		}
+		file.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no WordNet data files in %s", dir)
+	}
+	return entries, nil
+}
+
+// Parse one line of a WordNet data file:
+// offset lex_filenum ss_type w_cnt word lex_id [word lex_id...] p_cnt [pointers...] | gloss
+// The gloss holds the definition followed by quoted examples, separated by "; ".
+func parseWordNetSynset(line string, partOfSpeech string) ([]string, Definition, bool) {
+	// The license header lines start with spaces
+	if strings.HasPrefix(line, " ") {
+		return nil, Definition{}, false
+	}
+	parts := strings.SplitN(line, " | ", 2)
+	fields := strings.Fields(parts[0])
+	if len(parts) != 2 || len(fields) < 4 {
+		return nil, Definition{}, false
+	}
+
+	var wordCount int
+	if _, err := fmt.Sscanf(fields[3], "%x", &wordCount); err != nil || len(fields) < 4+2*wordCount {
+		return nil, Definition{}, false
+	}
+	var words []string
+	for i := 0; i < wordCount; i++ {
+		word := fields[4+2*i]
+		// Adjective markers such as "(a)" or "(p)" follow the word
+		if idx := strings.Index(word, "("); idx > 0 {
+			word = word[:idx]
+		}
+		words = append(words, lowerWord(strings.ReplaceAll(word, "_", " ")))
+	}
+	words = deduplicateStrings(words)
+
+	def := Definition{PartOfSpeech: partOfSpeech, Antonyms: []string{}}
+	for _, piece := range strings.Split(strings.TrimSpace(parts[1]), "; ") {
+		piece = strings.TrimSpace(piece)
+		if strings.HasPrefix(piece, "\"") {
+			if def.Example == "" {
+				def.Example = strings.Trim(piece, "\"")
+			}
+			continue
+		}
+		if def.Definition == "" {
+			def.Definition = piece
+		} else if def.Example == "" {
+			def.Definition += "; " + piece
+		}
+	}
+	return words, def, def.Definition != ""
+}
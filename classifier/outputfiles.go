@@ -0,0 +1,113 @@
+package classifier
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+)
+
+// Number of output files left untouched this run because their content was unchanged
+var skippedOutputFiles int
+
+// outputFile is a generated output file. With SkipUnchangedOutputs it collects the
+// content in memory and only writes it on Close if it differs from the file on disk,
+// so re-runs over an unchanged corpus don't touch timestamps or wake file watchers.
+type outputFile struct {
+	path        string
+	file        *os.File
+	content     bytes.Buffer
+	closed      bool
+	countHeader string // Title of a "<title> (count: N)" first line added on Close, if set
+}
+
+// Check that files can be created in dir by creating and removing a temporary one
+func checkWritable(dir string) error {
+	probe, err := ioutil.TempFile(dir, ".write-check*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// Create an output file, writing straight through unless unchanged files are skipped
+func createOutputFile(path string) (*outputFile, error) {
+	if !config.SkipUnchangedOutputs {
+		file, err := os.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		return &outputFile{path: path, file: file}, nil
+	}
+	return &outputFile{path: path}, nil
+}
+
+func (f *outputFile) Write(p []byte) (int, error) {
+	if f.file != nil {
+		return f.file.Write(p)
+	}
+	return f.content.Write(p)
+}
+
+// Close the file, writing the collected content if it changed. Closing twice is a no-op
+// so an explicit Close can be followed by a deferred one.
+func (f *outputFile) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+		if f.countHeader == "" {
+			return nil
+		}
+		// Appended and streamed files get their header once complete
+		data, err := ioutil.ReadFile(f.path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(f.path, withCountHeader(data, f.countHeader), 0644)
+	}
+	data := f.content.Bytes()
+	if f.countHeader != "" {
+		data = withCountHeader(data, f.countHeader)
+	}
+	if err := writeOutputFile(f.path, data); err != nil {
+		log.Printf("Error writing %s: %v\n", f.path, err)
+		return err
+	}
+	return nil
+}
+
+// Put a "<title> (count: N)" line above the entries of a word list, replacing the
+// header a resumed file already has. N counts the non-empty lines below it.
+func withCountHeader(data []byte, title string) []byte {
+	lines := strings.Split(string(data), "\n")
+	if len(lines) > 0 && strings.HasPrefix(lines[0], title+" (count: ") {
+		lines = lines[1:]
+	}
+	count := 0
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			count++
+		}
+	}
+	header := fmt.Sprintf("%s (count: %d)\n", title, count)
+	return []byte(header + strings.Join(lines, "\n"))
+}
+
+// Write a whole output file, skipping the write if the existing content is identical
+func writeOutputFile(path string, data []byte) error {
+	if config.SkipUnchangedOutputs {
+		if existing, err := ioutil.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+			skippedOutputFiles++
+			return nil
+		}
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,201 @@
+package classifier
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// Irregular verbs: base form -> past tense, past participle
+var irregularVerbForms = map[string][2]string{
+	"be": {"was", "been"}, "have": {"had", "had"}, "do": {"did", "done"},
+	"go": {"went", "gone"}, "run": {"ran", "run"}, "see": {"saw", "seen"},
+	"take": {"took", "taken"}, "come": {"came", "come"}, "make": {"made", "made"},
+	"say": {"said", "said"}, "get": {"got", "gotten"}, "know": {"knew", "known"},
+	"think": {"thought", "thought"}, "tell": {"told", "told"}, "find": {"found", "found"},
+	"give": {"gave", "given"}, "leave": {"left", "left"}, "feel": {"felt", "felt"},
+	"bring": {"brought", "brought"}, "buy": {"bought", "bought"}, "begin": {"began", "begun"},
+	"keep": {"kept", "kept"}, "hold": {"held", "held"}, "write": {"wrote", "written"},
+	"stand": {"stood", "stood"}, "hear": {"heard", "heard"}, "mean": {"meant", "meant"},
+	"meet": {"met", "met"}, "pay": {"paid", "paid"}, "sit": {"sat", "sat"},
+	"speak": {"spoke", "spoken"}, "lead": {"led", "led"}, "grow": {"grew", "grown"},
+	"lose": {"lost", "lost"}, "fall": {"fell", "fallen"}, "send": {"sent", "sent"},
+	"build": {"built", "built"}, "understand": {"understood", "understood"},
+	"draw": {"drew", "drawn"}, "break": {"broke", "broken"}, "spend": {"spent", "spent"},
+	"rise": {"rose", "risen"}, "drive": {"drove", "driven"}, "eat": {"ate", "eaten"},
+	"choose": {"chose", "chosen"}, "wear": {"wore", "worn"}, "put": {"put", "put"},
+	"set": {"set", "set"}, "cut": {"cut", "cut"}, "let": {"let", "let"},
+	"hit": {"hit", "hit"}, "read": {"read", "read"}, "sing": {"sang", "sung"},
+	"swim": {"swam", "swum"}, "drink": {"drank", "drunk"}, "fly": {"flew", "flown"},
+	"throw": {"threw", "thrown"}, "teach": {"taught", "taught"}, "catch": {"caught", "caught"},
+	"sleep": {"slept", "slept"}, "win": {"won", "won"}, "sell": {"sold", "sold"},
+	"forget": {"forgot", "forgotten"}, "become": {"became", "become"},
+}
+
+// Irregular noun plurals
+var irregularPlurals = map[string]string{
+	"child": "children", "man": "men", "woman": "women", "person": "people",
+	"foot": "feet", "tooth": "teeth", "mouse": "mice", "goose": "geese",
+	"ox": "oxen", "sheep": "sheep", "fish": "fish", "deer": "deer",
+	"series": "series", "species": "species", "criterion": "criteria",
+	"phenomenon": "phenomena", "analysis": "analyses", "crisis": "crises",
+	"thesis": "theses", "datum": "data", "cactus": "cacti", "knife": "knives",
+	"life": "lives", "wife": "wives", "leaf": "leaves", "half": "halves",
+	"basis": "bases", "axis": "axes", "lens": "lenses", "news": "news",
+}
+
+// Singular of an irregular plural, or "" when the word is not one
+func irregularSingular(plural string) string {
+	for singular, irregular := range irregularPlurals {
+		if irregular == plural {
+			return singular
+		}
+	}
+	return ""
+}
+
+func endsWithSibilant(word string) bool {
+	for _, suffix := range []string{"s", "x", "z", "ch", "sh"} {
+		if strings.HasSuffix(word, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// "try" -> "tries", "play" -> "plays"
+func endsWithConsonantY(word string) bool {
+	n := len(word)
+	return n >= 2 && word[n-1] == 'y' && !isVowel(word[n-2])
+}
+
+// Double the final consonant of one-syllable consonant-vowel-consonant words ("stop" -> "stopp")
+func doubleFinalConsonant(word string) string {
+	n := len(word)
+	if n >= 3 && !isVowel(word[n-1]) && isVowel(word[n-2]) && !isVowel(word[n-3]) &&
+		!strings.ContainsAny(word[:n-2], "aeiou") && !strings.ContainsRune("wxy", rune(word[n-1])) {
+		return word + word[n-1:]
+	}
+	return word
+}
+
+// Third-person singular of a verb, also used for regular noun plurals
+func addS(word string) string {
+	switch {
+	case endsWithConsonantY(word):
+		return word[:len(word)-1] + "ies"
+	case endsWithSibilant(word), strings.HasSuffix(word, "o") && !strings.HasSuffix(word, "oo"):
+		return word + "es"
+	}
+	return word + "s"
+}
+
+func addEd(word string) string {
+	switch {
+	case strings.HasSuffix(word, "e"):
+		return word + "d"
+	case endsWithConsonantY(word):
+		return word[:len(word)-1] + "ied"
+	}
+	return doubleFinalConsonant(word) + "ed"
+}
+
+func addIng(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ie"):
+		return word[:len(word)-2] + "ying"
+	case strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "ee") && len(word) > 2:
+		return word[:len(word)-1] + "ing"
+	}
+	return doubleFinalConsonant(word) + "ing"
+}
+
+// Verb paradigm: base, third-person singular, past tense, past participle, present participle
+func verbParadigm(base string) []string {
+	past, participle := addEd(base), addEd(base)
+	if forms, ok := irregularVerbForms[base]; ok {
+		past, participle = forms[0], forms[1]
+	}
+	thirdPerson := addS(base)
+	switch base {
+	case "be":
+		thirdPerson = "is"
+	case "have":
+		thirdPerson = "has"
+	}
+	return []string{base, thirdPerson, past, participle, addIng(base)}
+}
+
+// Noun paradigm: singular and plural
+func nounParadigm(base string) []string {
+	if plural, ok := irregularPlurals[base]; ok {
+		return []string{base, plural}
+	}
+	return []string{base, addS(base)}
+}
+
+// Base form for generating a paradigm, preferring the dictionary's inflection hint.
+// Otherwise inflections are only stripped when the word tagged on its own carries one
+// of the inflected tags, so base forms such as "series" or "proceed" stay unchanged.
+func paradigmBase(word string, inflectedTags []string) string {
+	word = lowerWord(word)
+	if cachedData, ok := wordCache[word]; ok {
+		if base, _ := baseFormOf(word, cachedData); base != "" {
+			return base
+		}
+	}
+	// Singular nouns ending in s, such as "species", are easily tagged as plurals
+	if _, ok := irregularPlurals[word]; ok {
+		return word
+	}
+	tag := tagWord(word)
+	for _, inflectedTag := range inflectedTags {
+		if tag != inflectedTag {
+			continue
+		}
+		if singular := irregularSingular(word); tag == "NNS" && singular != "" {
+			return singular
+		}
+		return lemmatizeWord(word, tag)
+	}
+	return word
+}
+
+// Write verb conjugation and noun plural skeletons for the known verbs and nouns
+func writeParadigms(path string, verbs []string, nouns []string) error {
+	file, err := createOutputFile(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	sections := []struct {
+		title    string
+		words    []string
+		tags     []string // Tags whose words are inflected forms
+		paradigm func(string) []string
+	}{
+		{"Verbs (base, -s, past, past participle, -ing)", verbs, []string{"VBG", "VBD", "VBN", "VBZ"}, verbParadigm},
+		{"Nouns (singular, plural)", nouns, []string{"NNS"}, nounParadigm},
+	}
+
+	for _, section := range sections {
+		if len(section.words) == 0 {
+			continue
+		}
+		writer.WriteString(section.title + "\n")
+
+		seen := make(map[string]bool)
+		for _, word := range section.words {
+			base := paradigmBase(word, section.tags)
+			if seen[base] || strings.Contains(base, " ") {
+				continue
+			}
+			seen[base] = true
+			writer.WriteString(fmt.Sprintf("%s%s: %s\n", indent(1), capitalizePhrase(base),
+				strings.Join(section.paradigm(base), ", ")))
+		}
+	}
+	return writer.Flush()
+}
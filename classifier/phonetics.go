@@ -0,0 +1,55 @@
+package classifier
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Region of a pronunciation recording, e.g. "US" for ".../hello-us.mp3". Only
+// two-letter accent suffixes count; anything else yields "".
+func audioRegion(url string) string {
+	accent := audioAccent(url)
+	if len(accent) != 2 {
+		return ""
+	}
+	return strings.ToUpper(accent)
+}
+
+// Transcriptions of an entry without repeated text. Entries cached before
+// transcriptions were recorded fall back to their single Phonetic.
+func entryTranscriptions(cachedData WordCache) []Transcription {
+	if len(cachedData.Phonetics) == 0 {
+		if cachedData.Phonetic == "" {
+			return nil
+		}
+		return []Transcription{{Text: cachedData.Phonetic}}
+	}
+
+	var result []Transcription
+	seen := make(map[string]int)
+	for _, transcription := range cachedData.Phonetics {
+		if i, ok := seen[transcription.Text]; ok {
+			// Keep the region of a repeated transcription if the first had none
+			if result[i].Region == "" {
+				result[i].Region = transcription.Region
+			}
+			continue
+		}
+		seen[transcription.Text] = len(result)
+		result = append(result, transcription)
+	}
+	return result
+}
+
+// List transcriptions with their regions, e.g. "/həˈləʊ/ (UK), /həˈloʊ/ (US)"
+func formatTranscriptions(transcriptions []Transcription) string {
+	var parts []string
+	for _, transcription := range transcriptions {
+		if transcription.Region != "" {
+			parts = append(parts, fmt.Sprintf("%s (%s)", transcription.Text, transcription.Region))
+		} else {
+			parts = append(parts, transcription.Text)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,105 @@
+package classifier
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Start background lookup workers that fetch words into the cache while files are
+// still being tokenized. The final frequency-ordered writing phase then mostly hits
+// the cache. Close the returned channel when tokenization ends and wait on done.
+//
+// Words discovered here are looked up even if later filters (rank window, exclusive
+// categories) drop them, trading some extra requests for overlapping the two phases.
+func startLookupPipeline(ctx context.Context) (chan<- string, <-chan struct{}) {
+	words := make(chan string, 1024)
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+	var countMutex sync.Mutex
+	looked := 0
+	for i := 0; i < performanceConfig.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for word := range words {
+				if ctx.Err() != nil {
+					continue
+				}
+				cacheWordDetails(ctx, lowerWord(word))
+				countMutex.Lock()
+				looked++
+				countMutex.Unlock()
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		log.Printf("Pipelined lookups finished: %d words\n", looked)
+		close(done)
+	}()
+
+	return words, done
+}
+
+// Whether a word still needs a dictionary request
+func needsLookup(word string) bool {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	if unknown, isUnknown := wordUnknown[word]; isUnknown {
+		return queryConfig.QueryForUnknownWords || isUnknownStale(unknown)
+	}
+	entry, exists := wordCache[word]
+	return !exists || isCacheEntryStale(entry)
+}
+
+// Look up every word not yet cached using Concurrency parallel workers, so the
+// sequential writing phase that follows only reads the cache. Cached words never
+// reach the workers. Progress counts completed lookups.
+func prefetchWordDetails(ctx context.Context, words []string) {
+	var pending []string
+	seen := make(map[string]bool)
+	for _, word := range words {
+		word = lowerWord(word)
+		if !seen[word] && needsLookup(word) {
+			pending = append(pending, word)
+		}
+		seen[word] = true
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	log.Printf("Looking up %d uncached words with %d workers\n", len(pending), performanceConfig.Concurrency)
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	var progressMutex sync.Mutex
+	completed := 0
+	for i := 0; i < performanceConfig.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for word := range jobs {
+				// Drain the remaining jobs without looking them up
+				if ctx.Err() != nil {
+					continue
+				}
+				cacheWordDetails(ctx, word)
+				progressMutex.Lock()
+				completed++
+				reportProgress("Dictionary lookup", word, completed, len(pending))
+				progressMutex.Unlock()
+			}
+		}()
+	}
+
+	for _, word := range pending {
+		jobs <- word
+	}
+	close(jobs)
+	wg.Wait()
+	endProgress()
+}
@@ -0,0 +1,60 @@
+package classifier
+
+import (
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"strconv"
+)
+
+// POSCounts holds the number of classified words per category in one input file
+type POSCounts struct {
+	File   string
+	Counts map[string]int
+	Total  int
+}
+
+// Count the classified words per category for one file
+func countPOS(inputFile string, categorizedWords map[string][]string) POSCounts {
+	counts := POSCounts{File: inputFile, Counts: make(map[string]int)}
+	for category, words := range categorizedWords {
+		counts.Counts[category] = len(words)
+		counts.Total += len(words)
+	}
+	return counts
+}
+
+// Write POSDistribution.csv with one row per file and each category's share in percent
+func writePOSDistribution(path string, inputDir string, rows []POSCounts) error {
+	file, err := createOutputFile(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	header := []string{"file", "words"}
+	for _, category := range categoryNames {
+		header = append(header, category+" %")
+	}
+	writer.Write(header)
+
+	for _, row := range rows {
+		name := row.File
+		if relPath, err := filepath.Rel(inputDir, row.File); err == nil {
+			name = filepath.ToSlash(relPath)
+		}
+		record := []string{name, strconv.Itoa(row.Total)}
+		for _, category := range categoryNames {
+			percentage := 0.0
+			if row.Total > 0 {
+				percentage = float64(row.Counts[category]) / float64(row.Total) * 100
+			}
+			record = append(record, fmt.Sprintf("%.1f", percentage))
+		}
+		writer.Write(record)
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
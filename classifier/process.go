@@ -0,0 +1,2181 @@
+package classifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jdkato/prose/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// Configuration structures
+type OutputConfig struct {
+	IncludePhonetic               bool               `yaml:"includePhonetic"`
+	IncludeOrigin                 bool               `yaml:"includeOrigin"`
+	IncludeAudio                  bool               `yaml:"includeAudio"` // Add pronunciation audio URLs to explanations and write <Category>_audio.txt
+	IncludeSynonyms               bool               `yaml:"includeSynonyms"`
+	IncludeAntonyms               bool               `yaml:"includeAntonyms"`
+	FilterNoExample               bool               `yaml:"filterDefinitionsWithoutExamples"`
+	CategoryDefinitionsOnly       bool               `yaml:"categoryDefinitionsOnly"`       // In a category's explanations show only definitions of its parts of speech (all of them if none match)
+	MaxDefinitions                int                `yaml:"maxDefinitions"`                // Definitions shown per word in explanations and Anki cards, after filterDefinitionsWithoutExamples (0 for no limit)
+	GenerateExplanations          bool               `yaml:"generateExplanations"`          // Toggle for explanation files
+	GenerateExampleSentences      bool               `yaml:"generateExampleSentences"`      // Toggle for example sentences files
+	MaxExampleSentences           int                `yaml:"maxExampleSentences"`           // Maximum number of example sentences per word
+	ExclusiveCategories           bool               `yaml:"exclusiveCategories"`           // Assign each word only to its dominant category
+	Morphology                    string             `yaml:"morphology"`                    // Morphological transform: none, stem or lemma
+	GenerateWordCloud             bool               `yaml:"generateWordCloud"`             // Toggle for word cloud frequency files
+	WordCloudFormat               string             `yaml:"wordCloudFormat"`               // Word cloud format: text (word:weight) or json
+	WordCloudTopN                 int                `yaml:"wordCloudTopN"`                 // Maximum words per word cloud, 0 means no limit
+	WordCloudNormalize            bool               `yaml:"wordCloudNormalize"`            // Scale word cloud weights to the 0-1 range
+	ExampleSenseLabels            bool               `yaml:"exampleSenseLabels"`            // Prefix examples with the sense they illustrate
+	GenerateManifest              bool               `yaml:"generateManifest"`              // Toggle for manifest.json listing all output files
+	IndentStyle                   string             `yaml:"indentStyle"`                   // Indentation for nested output lines: tabs or spaces
+	IndentSize                    int                `yaml:"indentSize"`                    // Spaces per indentation level when using spaces
+	IncludeBaseForm               bool               `yaml:"includeBaseForm"`               // Note when a word is an inflected form of another
+	RedirectToBaseForm            bool               `yaml:"redirectToBaseForm"`            // Show the base word's definitions for inflected forms
+	ExampleCapitalization         string             `yaml:"exampleCapitalization"`         // Example capitalization: smart, always or none
+	GenerateAnkiAudioDeck         bool               `yaml:"generateAnkiAudioDeck"`         // Toggle for an Anki deck with pronunciation audio
+	MinExampleLength              int                `yaml:"minExampleLength"`              // Minimum words in an example sentence, 0 means no limit
+	GenerateReverseIndex          bool               `yaml:"generateReverseIndex"`          // Toggle for a definition-to-word reverse index file
+	ReverseIndexSource            string             `yaml:"reverseIndexSource"`            // Reverse index clues: definitions or examples
+	ShuffleReverseIndex           bool               `yaml:"shuffleReverseIndex"`           // Shuffle the reverse index entries
+	GenerateSyllableTiers         bool               `yaml:"generateSyllableTiers"`         // Toggle for per-category syllable-count tier files
+	GenerateParadigms             bool               `yaml:"generateParadigms"`             // Toggle for verb conjugation and noun plural file
+	MinRank                       int                `yaml:"minRank"`                       // Skip words more frequent than this rank, 0 means no limit
+	MaxRank                       int                `yaml:"maxRank"`                       // Skip words less frequent than this rank, 0 means no limit
+	MinWordLength                 int                `yaml:"minWordLength"`                 // Skip words with fewer characters, 0 or 1 means no limit
+	MinFrequency                  int                `yaml:"minFrequency"`                  // Skip words seen fewer times in the corpus, 0 or 1 means no limit
+	IncludeWords                  []string           `yaml:"includeWords"`                  // Words kept regardless of minWordLength, minFrequency, rank and per-category limits
+	IncludeWordsFile              string             `yaml:"includeWordsFile"`              // Word list file (one per line) adding to includeWords
+	ExcludeWords                  []string           `yaml:"excludeWords"`                  // Words removed from every output and never looked up
+	ExcludeWordsFile              string             `yaml:"excludeWordsFile"`              // Word list file (one per line) adding to excludeWords
+	GenerateSpeechScript          bool               `yaml:"generateSpeechScript"`          // Toggle for a text-to-speech ready script file
+	CacheTokenization             bool               `yaml:"cacheTokenization"`             // Reuse tokenization of unchanged files across runs
+	CoverageWordList              string             `yaml:"coverageWordList"`              // Reference word list file for Coverage.txt, empty to skip
+	PipelineLookups               bool               `yaml:"pipelineLookups"`               // Look words up while files are still being tokenized
+	GeneratePOSDistribution       bool               `yaml:"generatePOSDistribution"`       // Toggle for per-file part-of-speech distribution CSV
+	ExplanationTemplate           string             `yaml:"explanationTemplate"`           // Go text/template for explanations, empty for built-in format
+	CategoryTemplates             map[string]string  `yaml:"categoryTemplates"`             // Per-category explanation templates overriding the global one
+	StripExampleAttributions      bool               `yaml:"stripExampleAttributions"`      // Remove trailing source citations from examples
+	CategorizeBy                  string             `yaml:"categorizeBy"`                  // Category source: tagger (prose POS tag) or dictionary
+	PostRunCommands               [][]string         `yaml:"postRunCommands"`               // Commands run after output is written, given the output directory
+	FilterStopwords               bool               `yaml:"filterStopwords"`               // Skip common function words ("the", "of", "and") before classification
+	StopwordsFile                 string             `yaml:"stopwordsFile"`                 // Extra stopwords, one per line
+	ReplaceDefaultStopwords       bool               `yaml:"replaceDefaultStopwords"`       // Use only StopwordsFile instead of extending the built-in list
+	GenerateSurfaceForms          bool               `yaml:"generateSurfaceForms"`          // List the original forms merged into each word by morphology in SurfaceForms.txt
+	GenerateAnki                  bool               `yaml:"generateAnki"`                  // Write Anki.txt, a flashcard deck for Anki's text import
+	AnkiFrontTemplate             string             `yaml:"ankiFrontTemplate"`             // HTML template for the card front (empty for word and phonetic)
+	AnkiBackTemplate              string             `yaml:"ankiBackTemplate"`              // HTML template for the card back (empty for definitions, examples, synonyms, antonyms)
+	GenerateCSV                   bool               `yaml:"generateCSV"`                   // Also write every known word with its definitions to Words.csv
+	GenerateJSON                  bool               `yaml:"generateJSON"`                  // Write results.json with all categories, words, definitions, unknown words and totals
+	Contractions                  string             `yaml:"contractions"`                  // Contractions split by the tokenizer: expand ("n't" -> not), drop (discard the clitics), or none
+	StatusOutput                  string             `yaml:"statusOutput"`                  // Where status messages go: both (log file and console), log, or console
+	HyphenatedWords               string             `yaml:"hyphenatedWords"`               // Hyphenated compounds ("well-being"): keep (one word), split (count the parts), or none (ignore them)
+	SplitUnknownCompounds         bool               `yaml:"splitUnknownCompounds"`         // With keep, replace compounds the dictionary doesn't know with their parts
+	GenerateSuggestions           bool               `yaml:"generateSuggestions"`           // Suggest close matches for unknown words in UnknownSuggestions.txt
+	SuggestionWordList            string             `yaml:"suggestionWordList"`            // File of words to suggest from; empty uses the cached words with definitions
+	MaxSuggestionDistance         int                `yaml:"maxSuggestionDistance"`         // Most edits (insert, delete, substitute) between an unknown word and a suggestion
+	GenerateEntities              bool               `yaml:"generateEntities"`              // List the people, organizations and locations prose recognizes in Entities.txt
+	MaxWordsPerCategory           int                `yaml:"maxWordsPerCategory"`           // Keep only each category's N most frequent words before lookups (0 for no limit)
+	MaxAllWords                   int                `yaml:"maxAllWords"`                   // Most known words written to AllWords.txt and its _ex/_es files (0 for no limit)
+	Recursive                     bool               `yaml:"recursive"`                     // Also read input files in subdirectories of the input directory
+	OutputDirectory               string             `yaml:"outputDirectory"`               // Directory to write results to, used as given; empty for <input directory name>_ewClassifiers
+	IncludeFrequency              bool               `yaml:"includeFrequency"`              // Append each word's count in the corpus to the category and AllWords lists ("Water (42)")
+	IncludeHeaders                bool               `yaml:"includeHeaders"`                // Start each category word list and AllWords.txt with a "<Category> (count: N)" line
+	GenerateCombined              bool               `yaml:"generateCombined"`              // Write one report with every category's words, counts, explanations and examples
+	CombinedFormat                string             `yaml:"combinedFormat"`                // Format of the combined report: text (Report.txt) or markdown (Report.md)
+	GenerateProperNounPhrases     bool               `yaml:"generateProperNounPhrases"`     // List runs of capitalized words ("United Nations") in ProperNounPhrases.txt
+	GenerateWordSources           bool               `yaml:"generateWordSources"`           // List the input files each word came from in WordSources.txt
+	Scripts                       []string           `yaml:"scripts"`                       // Unicode scripts words may be written in, e.g. [Cyrillic]; empty derives them from the query language
+	AllowApostrophes              bool               `yaml:"allowApostrophes"`              // Count words with an apostrophe inside, e.g. "o'clock"
+	AllowDigits                   bool               `yaml:"allowDigits"`                   // Count words mixing letters and digits, e.g. "mp3"
+	ExtraWordCharacters           string             `yaml:"extraWordCharacters"`           // Further characters words may contain besides letters, e.g. "&"
+	InputExtensions               []string           `yaml:"inputExtensions"`               // Input file types to read: .txt, .pdf, .md, .markdown, .html, .htm (empty for .txt and .pdf)
+	RandomSeed                    int64              `yaml:"randomSeed"`                    // Seed for example selection, quiz options and shuffling; 0 picks differently on every run
+	CorpusExamples                string             `yaml:"corpusExamples"`                // Input sentences as examples: prepend (before dictionary examples), substitute (instead of them), or empty
+	GenerateRunMetadata           bool               `yaml:"generateRunMetadata"`           // Write run_metadata.json describing how the output was produced
+	CEFRWordList                  string             `yaml:"cefrWordList"`                  // File of "word level" pairs rating words A1-C2
+	MaxDefinitionLevel            string             `yaml:"maxDefinitionLevel"`            // Highest CEFR level a definition may use without counting as hard
+	DefinitionLevelMode           string             `yaml:"definitionLevelMode"`           // Hard definitions: mark (asterisk hard words), filter (hide them), or empty to ignore
+	DehyphenateLineBreaks         bool               `yaml:"dehyphenateLineBreaks"`         // Rejoin words split across lines with a hyphen ("exam-" / "ple")
+	SkipUnchangedOutputs          bool               `yaml:"skipUnchangedOutputs"`          // Leave output files untouched when their content hasn't changed
+	GenerateQuiz                  bool               `yaml:"generateQuiz"`                  // Generate a multiple-choice definition quiz with an answer key
+	QuizDistractors               int                `yaml:"quizDistractors"`               // Number of wrong options per quiz question
+	Locale                        string             `yaml:"locale"`                        // BCP 47 tag whose casing rules lowercase words (e.g. "tr"); empty is language-neutral
+	LogCacheChanges               bool               `yaml:"logCacheChanges"`               // Append added, updated and evicted cache entries to cache_changes.log
+	CategoryWeights               map[string]float64 `yaml:"categoryWeights"`               // Per-category multipliers on frequency when ordering AllWords
+	DuplicateDefinitions          string             `yaml:"duplicateDefinitions"`          // Repeated definitions of a word: remove (identical ones), merge (also same text with other examples), or keep
+	MergeSimilarDefinitions       bool               `yaml:"mergeSimilarDefinitions"`       // Collapse near-duplicate definitions of a word
+	DefinitionSimilarityThreshold float64            `yaml:"definitionSimilarityThreshold"` // Token overlap (0-1) at which definitions count as duplicates
+	Categories                    []CategoryConfig   `yaml:"categories"`                    // Output categories with their POS tags, optional word lists and lookup setting; empty for the defaults
+}
+
+type QueryConfig struct {
+	QueryForUnknownWords bool     `yaml:"queryForUnknownWords"` // Whether to query words marked as unknown
+	Sources              []string `yaml:"sources"`              // Dictionary sources in priority order
+	MergeSources         bool     `yaml:"mergeSources"`         // Combine definitions from all sources instead of first-wins
+	MerriamWebsterAPIKey string   `yaml:"merriamWebsterAPIKey"` // Key for the merriamwebster source (dictionaryapi.com)
+	OfflineMode          bool     `yaml:"offlineMode"`          // Look words up only in offlineDictionary, without network access
+	OfflineDictionary    string   `yaml:"offlineDictionary"`    // JSON lexicon (word_cache.json format) or WordNet database directory
+	Language             string   `yaml:"language"`             // dictionaryapi.dev language code, e.g. "es" or "de" (default "en")
+	CacheTTL             string   `yaml:"cacheTTL"`             // Age after which cached definitions are refreshed, e.g. "720h" ("0" never expires)
+	UnknownRetryAfter    string   `yaml:"unknownRetryAfter"`    // Age after which words marked unknown are queried again, e.g. "720h" ("0" never retries)
+}
+
+type PerformanceConfig struct {
+	Concurrency        int     `yaml:"concurrency"`        // Number of dictionary lookups run in parallel
+	RateLimitPerSecond float64 `yaml:"rateLimitPerSecond"` // Maximum dictionary requests per second (0 for no limit)
+	MaxRetries         int     `yaml:"maxRetries"`         // Retries of a request answered with 429 or 5xx
+	RetryBaseDelayMs   int     `yaml:"retryBaseDelayMs"`   // First retry delay in milliseconds, doubled on each further retry
+	CacheFlushEvery    int     `yaml:"cacheFlushEvery"`    // Save the word caches after this many changed entries (0 saves after every lookup)
+	CacheFlushSeconds  int     `yaml:"cacheFlushSeconds"`  // Also save them when this many seconds passed since the last save
+	CompressCache      bool    `yaml:"compressCache"`      // Store the word cache gzip-compressed as word_cache.json.gz
+}
+
+type ProxyConfig struct {
+	HTTPProxy      string `yaml:"httpProxy"`
+	HTTPSProxy     string `yaml:"httpsProxy"`
+	RequestTimeout string `yaml:"requestTimeout"` // Time limit of each dictionary or audio request, e.g. "10s"
+}
+
+type Definition struct {
+	PartOfSpeech string
+	Definition   string
+	Example      string
+	Synonyms     []string
+	Antonyms     []string
+	Source       string // Dictionary source the definition came from
+}
+
+// A phonetic transcription with the region of its recording, if known
+type Transcription struct {
+	Text     string
+	Region   string // Accent such as "US" or "UK"
+	AudioURL string
+}
+
+type WordCache struct {
+	Definitions    []Definition
+	Phonetic       string          // Primary transcription
+	Phonetics      []Transcription // Every transcription the dictionary lists
+	Origin         string
+	Synonyms       []string
+	Antonyms       []string
+	BaseForm       string    // Base word when the entry is an inflected form, e.g. "good" for "better"
+	InflectionType string    // Kind of inflection, e.g. "comparative" or "past tense"
+	AudioURL       string    // Pronunciation audio file, if the dictionary provides one
+	CachedAt       time.Time // When the entry was fetched; zero for entries cached before timestamps were recorded
+}
+
+// Output categories in their canonical order, set from the categories config
+var categoryNames = []string{"Nouns", "ProperNouns", "Verbs", "Adjectives", "Adverbs", "OtherWords"}
+
+// Global variables
+var config OutputConfig
+var queryConfig QueryConfig
+var performanceConfig PerformanceConfig
+var proxyConfig ProxyConfig
+var wordCache = make(map[string]WordCache)
+var wordUnknown = make(map[string]unknownEntry)
+var cacheMutex sync.Mutex                  // Guards wordCache, wordUnknown and failedLookups while lookups run concurrently
+var failedLookups = make(map[string]error) // Lookups that failed this run, retried on the next one
+var cachePath = "word_cache.json"
+var unknownPath = "word_unknown.json"
+var forceIncludePath = "force_include.txt"
+var outputConfigPath = "outputConfig.yml"
+var proseModel *prose.Model
+
+// Helper functions
+func capitalizePhrase(phrase string) string {
+	words := strings.Fields(phrase)
+	for i, word := range words {
+		if len(word) > 0 {
+			words[i] = strings.ToUpper(string(word[0])) + strings.ToLower(word[1:])
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// Words conventionally written in lowercase even at the start of a sentence
+var lowercaseBrands = map[string]bool{
+	"adidas": true,
+	"ebay":   true,
+	"npm":    true,
+	"iphone": true,
+	"ipad":   true,
+	"ios":    true,
+	"macos":  true,
+}
+
+func capitalizeSentence(sentence string) string {
+	if len(sentence) == 0 {
+		return ""
+	}
+	switch config.ExampleCapitalization {
+	case "none":
+		return sentence
+	case "always":
+		runes := []rune(sentence)
+		runes[0] = unicode.ToUpper(runes[0])
+		return string(runes)
+	}
+
+	// Skip leading quotation marks and brackets so the first letter after them is capitalized
+	runes := []rune(sentence)
+	start := 0
+	for start < len(runes) && strings.ContainsRune("\"'“‘«([", runes[start]) {
+		start++
+	}
+	if start == len(runes) || !unicode.IsLower(runes[start]) {
+		// Numbers, symbols and already uppercase letters are left alone
+		return sentence
+	}
+
+	// Leave stylized words such as "iPhone" and known lowercase brands untouched
+	end := start
+	for end < len(runes) && !unicode.IsSpace(runes[end]) && !unicode.IsPunct(runes[end]) {
+		end++
+	}
+	firstWord := string(runes[start:end])
+	if lowercaseBrands[firstWord] || strings.ToLower(firstWord) != firstWord {
+		return sentence
+	}
+
+	runes[start] = unicode.ToUpper(runes[start])
+	return string(runes)
+}
+
+// Split "and/or" style tokens into their parts, dropping the empty parts left by
+// leading, trailing or doubled slashes ("word/", "/word", "a//b")
+func splitSlashSeparatedWords(text string) []string {
+	var parts []string
+	for _, part := range strings.Split(text, "/") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+func countFrequencies(content []string) map[string]int {
+	counts := make(map[string]int)
+	for _, item := range content {
+		counts[capitalizePhrase(item)]++
+	}
+	return counts
+}
+
+// Line of a word list file: the capitalized word, followed by its count when
+// includeFrequency is on ("Water (42)")
+func formatListEntry(word string, count int) string {
+	if config.IncludeFrequency {
+		return fmt.Sprintf("%s (%d)\n", capitalizePhrase(word), count)
+	}
+	return capitalizePhrase(word) + "\n"
+}
+
+func sortByFrequency(counts map[string]int) []string {
+	type itemFreq struct {
+		Item string
+		Freq int
+	}
+	var items []itemFreq
+	for item, freq := range counts {
+		items = append(items, itemFreq{Item: item, Freq: freq})
+	}
+	// Break ties alphabetically so equal counts don't follow map iteration order
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Freq != items[j].Freq {
+			return items[i].Freq > items[j].Freq
+		}
+		return items[i].Item < items[j].Item
+	})
+	var result []string
+	for _, item := range items {
+		result = append(result, item.Item)
+	}
+	return result
+}
+
+// Indentation for the given nesting level of the explanation and example output
+func indent(level int) string {
+	if config.IndentStyle == "spaces" {
+		size := config.IndentSize
+		if size <= 0 {
+			size = 4
+		}
+		return strings.Repeat(" ", size*level)
+	}
+	return strings.Repeat("\t", level)
+}
+
+// Remove empty lines from the text
+func removeEmptyLines(text string) string {
+	lines := strings.Split(text, "\n")
+	var nonEmptyLines []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			nonEmptyLines = append(nonEmptyLines, line)
+		}
+	}
+	return strings.Join(nonEmptyLines, "\n")
+}
+
+// Deduplicate a slice of strings
+func deduplicateStrings(slice []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, item := range slice {
+		if !seen[item] {
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Keep each word only in the category it was tagged with most often
+func assignPrimaryCategories(categorizedWords map[string][]string) map[string][]string {
+	// Count how often each word was tagged under each category
+	categoryCounts := make(map[string]map[string]int)
+	for category, words := range categorizedWords {
+		for _, word := range words {
+			if categoryCounts[word] == nil {
+				categoryCounts[word] = make(map[string]int)
+			}
+			categoryCounts[word][category]++
+		}
+	}
+
+	// Pick the dominant category, breaking ties by category order
+	primaryCategory := make(map[string]string)
+	for word, counts := range categoryCounts {
+		best := ""
+		for _, category := range categoryNames {
+			if counts[category] > counts[best] {
+				best = category
+			}
+		}
+		primaryCategory[word] = best
+	}
+
+	result := make(map[string][]string)
+	for category, words := range categorizedWords {
+		result[category] = []string{}
+		for _, word := range words {
+			if primaryCategory[word] == category {
+				result[category] = append(result[category], word)
+			}
+		}
+	}
+	return result
+}
+
+// Keep only words whose corpus frequency rank (1 = most frequent) lies within
+// MinRank..MaxRank; a zero bound is open. Forced words are always kept.
+func filterByRank(categorizedWords map[string][]string, allWords map[string]int, forcedWords []string) (map[string][]string, map[string]int) {
+	keep := make(map[string]bool)
+	for _, word := range forcedWords {
+		keep[word] = true
+	}
+	for i, word := range sortByFrequency(allWords) {
+		rank := i + 1
+		if rank >= config.MinRank && (config.MaxRank == 0 || rank <= config.MaxRank) {
+			keep[word] = true
+		}
+	}
+
+	return keepWords(categorizedWords, allWords, keep)
+}
+
+// Drop words shorter than MinWordLength characters or seen fewer than MinFrequency
+// times, except the included words
+func filterByThresholds(categorizedWords map[string][]string, allWords map[string]int, included map[string]bool) (map[string][]string, map[string]int) {
+	keep := make(map[string]bool)
+	for word, count := range allWords {
+		if included[word] || utf8.RuneCountInString(word) >= config.MinWordLength && count >= config.MinFrequency {
+			keep[word] = true
+		}
+	}
+	return keepWords(categorizedWords, allWords, keep)
+}
+
+// Keep each category's MaxWordsPerCategory most frequent words, counted within
+// the category, plus any forced words. Words left in no category lose their count.
+func capWordsPerCategory(categorizedWords map[string][]string, allWords map[string]int, forcedWords []string) (map[string][]string, map[string]int) {
+	forced := make(map[string]bool)
+	for _, word := range forcedWords {
+		forced[word] = true
+	}
+
+	cappedCategories := make(map[string][]string)
+	kept := make(map[string]bool)
+	for category, words := range categorizedWords {
+		counts := make(map[string]int)
+		for _, word := range words {
+			counts[word]++
+		}
+		keep := make(map[string]bool)
+		for i, word := range sortByFrequency(counts) {
+			if i < config.MaxWordsPerCategory || forced[word] {
+				keep[word] = true
+				kept[word] = true
+			}
+		}
+		cappedCategories[category] = []string{}
+		for _, word := range words {
+			if keep[word] {
+				cappedCategories[category] = append(cappedCategories[category], word)
+			}
+		}
+	}
+
+	cappedWords := make(map[string]int)
+	for word, count := range allWords {
+		if kept[word] {
+			cappedWords[word] = count
+		}
+	}
+	return cappedCategories, cappedWords
+}
+
+// Restrict the categorized words and frequency counts to the words in keep
+func keepWords(categorizedWords map[string][]string, allWords map[string]int, keep map[string]bool) (map[string][]string, map[string]int) {
+	filteredWords := make(map[string]int)
+	for word, count := range allWords {
+		if keep[word] {
+			filteredWords[word] = count
+		}
+	}
+
+	filteredCategories := make(map[string][]string)
+	for category, words := range categorizedWords {
+		filteredCategories[category] = []string{}
+		for _, word := range words {
+			if keep[word] {
+				filteredCategories[category] = append(filteredCategories[category], word)
+			}
+		}
+	}
+	return filteredCategories, filteredWords
+}
+
+// Configuration loading
+func loadConfig() OutputConfig {
+	defaultConfig := OutputConfig{
+		IncludePhonetic:               true,
+		IncludeOrigin:                 true,
+		IncludeAudio:                  false,
+		IncludeSynonyms:               true,
+		IncludeAntonyms:               true,
+		FilterNoExample:               false,
+		CategoryDefinitionsOnly:       false,
+		MaxDefinitions:                0,
+		GenerateExplanations:          true, // Default to true for backward compatibility
+		GenerateExampleSentences:      true, // Default to true for example sentences files
+		MaxExampleSentences:           0,    // Default to 0 meaning no limit
+		ExclusiveCategories:           false,
+		Morphology:                    "none",
+		GenerateWordCloud:             false,
+		WordCloudFormat:               "text",
+		WordCloudTopN:                 0, // Default to 0 meaning no limit
+		WordCloudNormalize:            false,
+		ExampleSenseLabels:            false, // Default to the flat example list
+		GenerateManifest:              false,
+		IndentStyle:                   "tabs",
+		IndentSize:                    4,
+		IncludeBaseForm:               false,
+		RedirectToBaseForm:            false,
+		ExampleCapitalization:         "smart",
+		GenerateAnkiAudioDeck:         false,
+		MinExampleLength:              0, // Default to 0 meaning no limit
+		GenerateReverseIndex:          false,
+		ReverseIndexSource:            "definitions",
+		ShuffleReverseIndex:           false,
+		GenerateSyllableTiers:         false,
+		GenerateParadigms:             false,
+		MinRank:                       0, // Default to 0 meaning no limit
+		MaxRank:                       0, // Default to 0 meaning no limit
+		MinWordLength:                 0,
+		MinFrequency:                  0,
+		IncludeWords:                  []string{},
+		IncludeWordsFile:              "",
+		ExcludeWords:                  []string{},
+		ExcludeWordsFile:              "",
+		GenerateSpeechScript:          false,
+		CacheTokenization:             false,
+		CoverageWordList:              "",
+		PipelineLookups:               false,
+		GeneratePOSDistribution:       false,
+		ExplanationTemplate:           "",
+		CategoryTemplates:             map[string]string{},
+		StripExampleAttributions:      false,
+		CategorizeBy:                  "tagger",
+		PostRunCommands:               [][]string{},
+		FilterStopwords:               false,
+		StopwordsFile:                 "",
+		ReplaceDefaultStopwords:       false,
+		GenerateSurfaceForms:          false,
+		GenerateAnki:                  false,
+		AnkiFrontTemplate:             "",
+		AnkiBackTemplate:              "",
+		GenerateCSV:                   false,
+		GenerateJSON:                  false,
+		Contractions:                  "expand",
+		StatusOutput:                  "both",
+		HyphenatedWords:               "keep",
+		SplitUnknownCompounds:         true,
+		GenerateSuggestions:           false,
+		SuggestionWordList:            "",
+		MaxSuggestionDistance:         2,
+		GenerateEntities:              false,
+		MaxWordsPerCategory:           0,
+		MaxAllWords:                   0,
+		Recursive:                     false,
+		OutputDirectory:               "",
+		IncludeFrequency:              false,
+		IncludeHeaders:                false,
+		GenerateCombined:              false,
+		CombinedFormat:                "text",
+		GenerateProperNounPhrases:     false,
+		GenerateWordSources:           false,
+		CorpusExamples:                "",
+		RandomSeed:                    0,
+		InputExtensions:               defaultInputExtensions,
+		Scripts:                       []string{},
+		AllowApostrophes:              false,
+		AllowDigits:                   false,
+		ExtraWordCharacters:           "",
+		GenerateRunMetadata:           false,
+		CEFRWordList:                  "",
+		MaxDefinitionLevel:            "B1",
+		DefinitionLevelMode:           "",
+		DehyphenateLineBreaks:         false,
+		SkipUnchangedOutputs:          false,
+		GenerateQuiz:                  false,
+		QuizDistractors:               3,
+		Locale:                        "",
+		LogCacheChanges:               false,
+		CategoryWeights:               map[string]float64{},
+		DuplicateDefinitions:          "remove",
+		MergeSimilarDefinitions:       false,
+		DefinitionSimilarityThreshold: 0.8,
+		Categories:                    defaultCategories,
+	}
+
+	configPath := outputConfigPath
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		yamlData, _ := yaml.Marshal(defaultConfig)
+		ioutil.WriteFile(configPath, yamlData, 0644)
+		return defaultConfig
+	}
+
+	yamlFile, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return defaultConfig
+	}
+
+	var config OutputConfig
+	if err := yaml.Unmarshal(yamlFile, &config); err != nil {
+		return defaultConfig
+	}
+	return config
+}
+
+func loadQueryConfig() QueryConfig {
+	defaultConfig := QueryConfig{
+		QueryForUnknownWords: false, // Default to not query unknown words
+		Sources:              defaultSources,
+		MergeSources:         false,
+		MerriamWebsterAPIKey: "",
+		OfflineMode:          false,
+		OfflineDictionary:    "",
+		Language:             "en",
+		CacheTTL:             "0",
+		UnknownRetryAfter:    "0",
+	}
+
+	configPath := "queryConfig.yml"
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		yamlData, _ := yaml.Marshal(defaultConfig)
+		ioutil.WriteFile(configPath, yamlData, 0644)
+		return defaultConfig
+	}
+
+	yamlFile, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return defaultConfig
+	}
+
+	var config QueryConfig
+	if err := yaml.Unmarshal(yamlFile, &config); err != nil {
+		return defaultConfig
+	}
+	if len(config.Sources) == 0 {
+		config.Sources = defaultSources
+	}
+	return config
+}
+
+func loadPerformanceConfig() PerformanceConfig {
+	defaultConfig := PerformanceConfig{
+		Concurrency:        4,
+		RateLimitPerSecond: 0,
+		MaxRetries:         3,
+		RetryBaseDelayMs:   500,
+		CacheFlushEvery:    100,
+		CacheFlushSeconds:  30,
+		CompressCache:      false,
+	}
+
+	configPath := "performanceConfig.yml"
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		yamlData, _ := yaml.Marshal(defaultConfig)
+		ioutil.WriteFile(configPath, yamlData, 0644)
+		return defaultConfig
+	}
+
+	yamlFile, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return defaultConfig
+	}
+
+	// Start from the defaults so settings missing from older files keep their default
+	config := defaultConfig
+	if err := yaml.Unmarshal(yamlFile, &config); err != nil {
+		return defaultConfig
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	return config
+}
+
+func loadProxyConfig() ProxyConfig {
+	defaultConfig := ProxyConfig{
+		HTTPProxy:      "",
+		HTTPSProxy:     "",
+		RequestTimeout: defaultRequestTimeout.String(),
+	}
+
+	configPath := "proxy.yml"
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		yamlData, _ := yaml.Marshal(defaultConfig)
+		ioutil.WriteFile(configPath, yamlData, 0644)
+		return defaultConfig
+	}
+
+	yamlFile, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return defaultConfig
+	}
+
+	var config ProxyConfig
+	if err := yaml.Unmarshal(yamlFile, &config); err != nil {
+		return defaultConfig
+	}
+	return config
+}
+
+// Cache management. A cache only found in the format compressCache doesn't ask
+// for is read from there and converted on the next save.
+func loadWordCache() {
+	path := wordCachePath()
+	if !fileExists(path) {
+		path = otherWordCachePath()
+		if !fileExists(path) {
+			return
+		}
+		cacheMutex.Lock()
+		wordCacheDirty = true
+		cacheMutex.Unlock()
+	}
+
+	cache, migrated := loadVersionedWordCache(path)
+	wordCache = cache
+	if migrated {
+		saveWordCache()
+	}
+}
+
+func saveWordCache() {
+	if err := writeWordCache(); err != nil {
+		log.Printf("Error saving %s: %v\n", wordCachePath(), err)
+	}
+}
+
+// Write word_cache.json atomically, so an interrupted save keeps the previous
+// cache, and remove the cache file of the other format
+func writeWordCache() error {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	data, err := json.MarshalIndent(wordCacheFile{Version: wordCacheVersion, Words: wordCache}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeCacheFile(wordCachePath(), data); err != nil {
+		return err
+	}
+	if err := os.Remove(otherWordCachePath()); err != nil && !os.IsNotExist(err) {
+		log.Printf("Error removing %s: %v\n", otherWordCachePath(), err)
+	}
+	return nil
+}
+
+// Load unknown words
+func loadWordUnknown() {
+	if _, err := os.Stat(unknownPath); os.IsNotExist(err) {
+		return
+	}
+
+	data, err := ioutil.ReadFile(unknownPath)
+	if err != nil {
+		return
+	}
+
+	unknown, migrated, err := decodeWordUnknown(data)
+	if err != nil {
+		unknown = make(map[string]unknownEntry)
+	}
+	wordUnknown = unknown
+	if migrated {
+		backup := unknownPath + ".v1.bak"
+		if err := ioutil.WriteFile(backup, data, 0644); err != nil {
+			log.Printf("Error backing up %s: %v\n", unknownPath, err)
+		}
+		Statusf("Migrated %s to timestamped entries (%d words), original kept as %s\n", unknownPath, len(unknown), backup)
+		saveWordUnknown()
+	}
+}
+
+func saveWordUnknown() {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	data, err := json.MarshalIndent(wordUnknown, "", "  ")
+	if err != nil {
+		return
+	}
+	ioutil.WriteFile(unknownPath, data, 0644)
+}
+
+const defaultRequestTimeout = 10 * time.Second
+
+// Time limit of each HTTP request, from the requestTimeout setting in proxy.yml
+var requestTimeout = defaultRequestTimeout
+
+// Parse the requestTimeout setting, falling back to the default when it is
+// missing or invalid
+func parseRequestTimeout(value string) time.Duration {
+	if value == "" {
+		return defaultRequestTimeout
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		log.Printf("Invalid requestTimeout '%s' in proxy.yml, using %s\n", value, defaultRequestTimeout)
+		return defaultRequestTimeout
+	}
+	return timeout
+}
+
+// Client shared by every lookup and audio download, so the workers reuse
+// keep-alive connections. Rebuilt by New from proxy.yml.
+var httpClient = createHTTPClient()
+
+// Build an HTTP client from proxy.yml. Its transport closes idle connections
+// after a while and keeps one per lookup worker for reuse.
+func createHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if performanceConfig.Concurrency > transport.MaxIdleConnsPerHost {
+		transport.MaxIdleConnsPerHost = performanceConfig.Concurrency
+	}
+
+	if proxyConfig.HTTPSProxy != "" {
+		proxyURL, err := url.Parse(proxyConfig.HTTPSProxy)
+		if err == nil {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	} else if proxyConfig.HTTPProxy != "" {
+		proxyURL, err := url.Parse(proxyConfig.HTTPProxy)
+		if err == nil {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	} else {
+		// Without a proxy in proxy.yml use HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+		transport.Proxy = http.ProxyFromEnvironment
+	}
+
+	return &http.Client{
+		Timeout:   requestTimeout,
+		Transport: transport,
+	}
+}
+
+// Return the cached entry for a word, looking it up first if it isn't cached yet.
+// Returns errWordNotFound if the dictionary has no entry for the word, or a
+// *LookupError if the lookup failed and should be retried on a later run. Safe to
+// call from several goroutines: the dictionary request runs without holding cacheMutex.
+func cacheWordDetails(ctx context.Context, word string) (WordCache, error) {
+	cacheMutex.Lock()
+	unknown, isUnknown := wordUnknown[word]
+	cachedData, exists := wordCache[word]
+	failure := failedLookups[word]
+	cacheMutex.Unlock()
+
+	// If configured not to query unknown words, return empty result until UnknownRetryAfter passes
+	if isUnknown && !queryConfig.QueryForUnknownWords && !isUnknownStale(unknown) {
+		countCacheResult(word, true)
+		return WordCache{}, errWordNotFound
+	}
+	// Entries older than CacheTTL are refreshed, but kept if the refresh fails
+	stale := exists && isCacheEntryStale(cachedData)
+	if exists && !stale {
+		countCacheResult(word, true)
+		return cachedData, nil
+	}
+	countCacheResult(word, false)
+	// Don't hit the network again for a word that already failed this run
+	if failure != nil {
+		if stale {
+			return cachedData, nil
+		}
+		return WordCache{}, failure
+	}
+
+	staleData := cachedData
+	startLookupTimer()
+	cachedData, err := lookupWord(ctx, word)
+	stopLookupTimer()
+	if err != nil {
+		// Leave the word out of both caches so a later run retries it. A cancelled
+		// lookup didn't fail, so it is tried again even within this run.
+		if ctx.Err() == nil {
+			cacheMutex.Lock()
+			failedLookups[word] = err
+			cacheMutex.Unlock()
+		}
+		if stale {
+			log.Printf("Keeping stale cache entry for '%s': %v\n", word, err)
+			return staleData, nil
+		}
+		return WordCache{}, err
+	}
+	cachedData.CachedAt = time.Now()
+
+	// Record the base word if the dictionary describes this one as an inflected form
+	cachedData.BaseForm, cachedData.InflectionType = detectBaseForm(word, cachedData.Definitions)
+
+	cacheMutex.Lock()
+	_, wasUnknown := wordUnknown[word]
+	found := len(cachedData.Definitions) > 0
+	if found {
+		// Definitions were found, so cache them and remove the word from unknown words
+		wordCache[word] = cachedData
+		if stale {
+			logCacheChange("updated", "wordCache", word, fmt.Sprintf("refreshed, %d definitions", len(cachedData.Definitions)))
+		} else {
+			logCacheChange("added", "wordCache", word, fmt.Sprintf("%d definitions", len(cachedData.Definitions)))
+		}
+		if wasUnknown {
+			delete(wordUnknown, word)
+			logCacheChange("evicted", "wordUnknown", word, "definitions found")
+		}
+	} else {
+		// No definitions found, mark as unknown
+		if stale {
+			delete(wordCache, word)
+			logCacheChange("evicted", "wordCache", word, "no definitions on refresh")
+		}
+		if wasUnknown {
+			logCacheChange("updated", "wordUnknown", word, "still no definitions")
+		} else {
+			logCacheChange("added", "wordUnknown", word, "no definitions")
+			countNewUnknown()
+		}
+		wordUnknown[word] = unknownEntry{MarkedAt: time.Now()}
+	}
+	cacheMutex.Unlock()
+
+	markCachesDirty(found || stale, !found || wasUnknown)
+	if !found {
+		return cachedData, errWordNotFound
+	}
+	return cachedData, nil
+}
+
+// Format the details of a word for the explanation file of category, or for no
+// particular category when it is empty. The error tells a word missing from the
+// dictionary (errWordNotFound) apart from a failed lookup (*LookupError).
+func fetchWordDetails(ctx context.Context, word string, category string) (string, error) {
+	word = lowerWord(word)
+
+	cachedData, err := cacheWordDetails(ctx, word)
+	if err != nil {
+		return fmt.Sprintf("%s\n%sNo details available.\n", capitalizePhrase(word), indent(1)), err
+	}
+
+	// Format output with the new layout
+	var output strings.Builder
+	capitalized := capitalizePhrase(word)
+
+	// Put word and phonetic on the same line
+	if cachedData.Phonetic != "" && config.IncludePhonetic {
+		output.WriteString(fmt.Sprintf("%s %s\n", capitalized, cachedData.Phonetic))
+	} else {
+		output.WriteString(fmt.Sprintf("%s\n", capitalized))
+	}
+
+	// List every transcription when the dictionary has more than one, e.g. UK and US
+	if transcriptions := entryTranscriptions(cachedData); config.IncludePhonetic && len(transcriptions) > 1 {
+		output.WriteString(fmt.Sprintf("%sPronunciations: %s\n", indent(1), formatTranscriptions(transcriptions)))
+	}
+
+	// Add origin if available and enabled
+	if config.IncludeOrigin && cachedData.Origin != "" {
+		output.WriteString(fmt.Sprintf("%sOrigin: %s\n", indent(1), cachedData.Origin))
+	}
+
+	// Add the pronunciation recording if available and enabled
+	if config.IncludeAudio && cachedData.AudioURL != "" {
+		output.WriteString(fmt.Sprintf("%sAudio: %s\n", indent(1), cachedData.AudioURL))
+	}
+
+	// Note the base word for inflected forms and optionally show its definitions instead
+	baseForm, inflectionType := baseFormOf(word, cachedData)
+	if baseForm != "" && (config.IncludeBaseForm || config.RedirectToBaseForm) {
+		output.WriteString(fmt.Sprintf("%sForm of: %s (%s)\n", indent(1), capitalizePhrase(baseForm), inflectionType))
+	}
+	if baseForm != "" && config.RedirectToBaseForm {
+		if baseData, err := cacheWordDetails(ctx, baseForm); err == nil {
+			cachedData.Definitions = baseData.Definitions
+		}
+	}
+
+	// Hide definitions using words above the learner's level
+	if cefrWordLevels != nil && config.DefinitionLevelMode == "filter" {
+		cachedData.Definitions = filterHardDefinitions(cachedData.Definitions)
+	}
+	cachedData.Definitions = definitionsForCategory(cachedData.Definitions, category)
+
+	// Check if there are definitions available
+	if len(cachedData.Definitions) == 0 {
+		output.WriteString(fmt.Sprintf("%s%s: No details available.\n", indent(1), capitalized))
+		return output.String(), nil
+	}
+
+	// Process definitions with the new format
+	defNumber := 0
+	for _, def := range cachedData.Definitions {
+		if config.FilterNoExample && exampleOf(def) == "" {
+			continue
+		}
+		if definitionLimitReached(defNumber) {
+			break
+		}
+
+		// Number only the definitions that are shown so filtering leaves no gaps
+		defNumber++
+
+		// Write definition with number and word prefix, noting its source when sources are merged
+		definition := def.Definition
+		if cefrWordLevels != nil && config.DefinitionLevelMode == "mark" {
+			definition = markHardWords(definition)
+		}
+		if queryConfig.MergeSources && def.Source != "" {
+			definition += " [" + def.Source + "]"
+		}
+		output.WriteString(fmt.Sprintf("%s%s %d, %s: %s\n",
+			indent(1), capitalized, defNumber, def.PartOfSpeech, definition))
+
+		// Add example if available, with word and number prefix
+		if example := exampleOf(def); example != "" {
+			output.WriteString(fmt.Sprintf("%s%s %d Example: %s\n",
+				indent(2), capitalized, defNumber, example))
+		}
+
+		// Add synonyms if enabled and available, with word and number prefix
+		if config.IncludeSynonyms && len(def.Synonyms) > 0 {
+			output.WriteString(fmt.Sprintf("%s%s %d Synonyms: %s\n",
+				indent(2), capitalized, defNumber, strings.Join(def.Synonyms, ", ")))
+		}
+
+		// Add antonyms if enabled and available, with word and number prefix
+		if config.IncludeAntonyms && len(def.Antonyms) > 0 {
+			output.WriteString(fmt.Sprintf("%s%s %d Antonyms: %s\n",
+				indent(2), capitalized, defNumber, strings.Join(def.Antonyms, ", ")))
+		}
+	}
+
+	return removeEmptyLines(output.String()), nil
+}
+
+// Whether MaxDefinitions definitions have been shown already
+func definitionLimitReached(shown int) bool {
+	return config.MaxDefinitions > 0 && shown >= config.MaxDefinitions
+}
+
+// Check if a word has details
+func hasWordDetails(word string) bool {
+	word = lowerWord(word)
+
+	// Check if the word is in the unknown words database
+	if _, isUnknown := wordUnknown[word]; isUnknown {
+		return false
+	}
+
+	// Check if the word is in the cache and has definitions
+	if cachedData, exists := wordCache[word]; exists {
+		return len(cachedData.Definitions) > 0
+	}
+
+	return false
+}
+
+// Function to generate example sentences file for a word
+func generateExampleSentencesContent(word string) string {
+	word = lowerWord(word)
+
+	// Skip if word is in unknown words
+	if _, isUnknown := wordUnknown[word]; isUnknown {
+		return ""
+	}
+
+	cachedData, exists := wordCache[word]
+
+	if !exists || len(cachedData.Definitions) == 0 {
+		return ""
+	}
+
+	var output strings.Builder
+	capitalized := capitalizePhrase(word)
+	output.WriteString(capitalized + "\n")
+
+	// Sentences from the input files come first, up to the example limit
+	maxExamples := config.MaxExampleSentences
+	usage := corpusSentences[word]
+	if config.CorpusExamples == "" {
+		usage = nil
+	}
+	if maxExamples > 0 && len(usage) > maxExamples {
+		usage = usage[:maxExamples]
+	}
+	for _, sentence := range usage {
+		output.WriteString(indent(1) + sentence + "\n")
+	}
+	if maxExamples > 0 {
+		maxExamples -= len(usage)
+		if maxExamples == 0 {
+			return removeEmptyLines(output.String())
+		}
+	}
+
+	// Collect all definitions with examples first, keeping their sense context.
+	// Input sentences replace them in substitute mode whenever there are any.
+	var examples []Definition
+	if config.CorpusExamples != "substitute" || len(usage) == 0 {
+		for _, def := range cachedData.Definitions {
+			if exampleOf(def) != "" {
+				examples = append(examples, def)
+			}
+		}
+	}
+
+	if len(examples) == 0 && len(usage) == 0 {
+		return ""
+	}
+
+	// Apply max example sentence limit if configured
+	totalExamples := len(examples)
+
+	// If maxExamples is 0 or greater than or equal to total examples, use all examples
+	if maxExamples == 0 || maxExamples >= totalExamples {
+		for _, def := range examples {
+			output.WriteString(indent(1) + formatExampleSentence(def) + "\n")
+		}
+	} else {
+		// Randomly select maxExamples unique examples
+		// Create a copy of the examples slice to avoid modifying the original
+		examplesCopy := make([]Definition, len(examples))
+		copy(examplesCopy, examples)
+
+		r := randFor("examples:" + word)
+
+		// Select maxExamples unique examples
+		selectedExamples := make([]Definition, 0, maxExamples)
+		for i := 0; i < maxExamples; i++ {
+			// Generate random index
+			randIndex := r.Intn(len(examplesCopy))
+			// Add the example at the random index to selected examples
+			selectedExamples = append(selectedExamples, examplesCopy[randIndex])
+			// Remove the selected example to avoid duplicates
+			examplesCopy = append(examplesCopy[:randIndex], examplesCopy[randIndex+1:]...)
+		}
+
+		// Write selected examples to output
+		for _, def := range selectedExamples {
+			output.WriteString(indent(1) + formatExampleSentence(def) + "\n")
+		}
+	}
+
+	return removeEmptyLines(output.String())
+}
+
+// The definition's example, or empty if it is shorter than the configured minimum
+func exampleOf(def Definition) string {
+	if config.MinExampleLength > 0 && len(strings.Fields(def.Example)) < config.MinExampleLength {
+		return ""
+	}
+	return def.Example
+}
+
+// Format a definition's example, optionally labelled with the sense it illustrates
+func formatExampleSentence(def Definition) string {
+	// Make sure the first letter is capitalized
+	example := capitalizeSentence(def.Example)
+	if !config.ExampleSenseLabels {
+		return example
+	}
+	return fmt.Sprintf("(%s: %s) %s", def.PartOfSpeech, senseLabel(def.Definition), example)
+}
+
+// Shorten a definition to a few words so it can label an example
+func senseLabel(definition string) string {
+	const maxLabelWords = 6
+	words := strings.Fields(strings.TrimRight(definition, "."))
+	if len(words) <= maxLabelWords {
+		return strings.Join(words, " ")
+	}
+	return strings.Join(words[:maxLabelWords], " ") + "..."
+}
+
+// Load prose's tagger model once so every file reuses it
+func loadProseModel() (model *prose.Model, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("prose failed to load its models: %v", r)
+		}
+	}()
+	return prose.ModelFromData("en"), nil
+}
+
+// Create an NLP document, turning panics from prose's model loading into errors
+func newProseDocument(content string) (doc *prose.Document, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("prose failed to process text: %v", r)
+		}
+	}()
+
+	if proseModel == nil {
+		return prose.NewDocument(content)
+	}
+	// Only tokens, tags and optionally entities are used, so skip sentence segmentation
+	return prose.NewDocument(content,
+		prose.UsingModel(proseModel),
+		prose.WithSegmentation(false),
+		prose.WithExtraction(config.GenerateEntities))
+}
+
+// Check that prose's tokenizer and tagger work before processing any file
+func checkProseModels() error {
+	model, err := loadProseModel()
+	if err != nil {
+		return err
+	}
+	proseModel = model
+
+	doc, err := newProseDocument("The quick brown fox jumps over the lazy dog.")
+	if err != nil {
+		return err
+	}
+	tokens := doc.Tokens()
+	if len(tokens) == 0 || tokens[0].Tag == "" {
+		return fmt.Errorf("tagger produced no part-of-speech tags")
+	}
+	return nil
+}
+
+// Move each word to the category of its primary (first) dictionary definition.
+// Words without dictionary details keep the category prose tagged them with.
+func recategorizeByDictionary(ctx context.Context, categorizedWords map[string][]string) map[string][]string {
+	var uniqueWords []string
+	seen := make(map[string]bool)
+	for _, words := range categorizedWords {
+		for _, word := range words {
+			if !seen[word] {
+				seen[word] = true
+				uniqueWords = append(uniqueWords, word)
+			}
+		}
+	}
+
+	lookupWords := lookupWordSet(categorizedWords)
+	dictionaryCategory := make(map[string]string)
+	for i, word := range uniqueWords {
+		if ctx.Err() != nil {
+			break
+		}
+		reportProgress("Dictionary categorization", word, i+1, len(uniqueWords))
+		// Words from a category word list stay where the list put them
+		if _, listed := categoryByWord[word]; listed {
+			continue
+		}
+		// So do words only found in categories that skip lookups
+		if !lookupWords[word] {
+			continue
+		}
+		fetchWordDetails(ctx, word, "")
+		if cachedData, ok := wordCache[lowerWord(word)]; ok && len(cachedData.Definitions) > 0 {
+			dictionaryCategory[word] = categoryForPartOfSpeech(cachedData.Definitions[0].PartOfSpeech)
+		}
+	}
+	endProgress()
+
+	result := make(map[string][]string)
+	for _, category := range categoryNames {
+		result[category] = []string{}
+	}
+	for category, words := range categorizedWords {
+		for _, word := range words {
+			target := category
+			if dictCategory, ok := dictionaryCategory[word]; ok {
+				target = dictCategory
+			}
+			if target != "" {
+				result[target] = append(result[target], word)
+			}
+		}
+	}
+	return result
+}
+
+// Read and process a single file, returning the categorized words and all words
+func processFile(inputFile string) (map[string][]string, map[string]int, error) {
+	// Read input file with the reader for its type
+	content, err := readInputText(inputFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Reuse the stored tokenization if this content was processed before
+	cacheKey := tokenCacheKey(content)
+	if config.CacheTokenization {
+		if cached, ok := tokenCache[cacheKey]; ok &&
+			(cached.ProperNounPhrases != nil || !config.GenerateProperNounPhrases) &&
+			(cached.SurfaceForms != nil || !config.GenerateSurfaceForms) &&
+			(cached.Sentences != nil || config.CorpusExamples == "") &&
+			(cached.Entities != nil || !config.GenerateEntities) {
+			log.Printf("Using cached tokenization for file: %s\n", inputFile)
+			addProperNounPhrases(cached.ProperNounPhrases)
+			addSurfaceForms(cached.SurfaceForms)
+			addCorpusSentences(cached.Sentences)
+			addEntities(cached.Entities)
+			return cached.CategorizedWords, cached.AllWords, nil
+		}
+	}
+
+	// Create NLP document
+	doc, err := newProseDocument(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	categorizedWords := map[string][]string{}
+	allWords := map[string]int{}
+	forms := map[string]map[string]int{}
+
+	// Process tokens; punctuation is skipped so progress counts only words
+	tokens := doc.Tokens()
+	wordIndexes := wordTokenIndexes(tokens)
+	totalTokens := len(wordIndexes)
+
+	// Find the sentence of every token when input sentences serve as examples
+	var sentences map[string][]string
+	var sentenceTexts []string
+	var sentenceIndexes []int
+	if config.CorpusExamples != "" {
+		sentenceTexts, err = segmentSentences(content)
+		if err != nil {
+			return nil, nil, err
+		}
+		sentenceIndexes = tokenSentenceIndexes(content, sentenceTexts, tokens)
+		sentences = map[string][]string{}
+	}
+
+	Statusf("Processing file: %s (%d word tokens)\n", inputFile, totalTokens)
+
+	for n, i := range wordIndexes {
+		tok := tokens[i]
+		text := lowerWord(tok.Text)
+		reportProgress("Classifying text", text, n+1, totalTokens)
+
+		// Resolve contractions, then process slash-separated words
+		var wordParts []string
+		for _, word := range contractionWords(tokens, i) {
+			wordParts = append(wordParts, splitSlashSeparatedWords(word)...)
+		}
+		for _, word := range splitCompounds(wordParts, tok.Tag) {
+			part := word.Text
+			if isCountedWord(part) && !isStopword(part) {
+				surface := part
+				part = morphologyTransform(part, word.Tag)
+				category := categoryForWord(part, word.Tag)
+				if category == "" {
+					// No configured category collects this tag
+					continue
+				}
+				recordSurfaceForm(forms, part, surface)
+				if sentences != nil && sentenceIndexes[i] >= 0 {
+					recordCorpusSentence(sentences, part, sentenceTexts[sentenceIndexes[i]])
+				}
+				allWords[part]++
+				categorizedWords[category] = append(categorizedWords[category], part)
+			}
+		}
+	}
+
+	phrases := findProperNounPhrases(tokens)
+	addProperNounPhrases(phrases)
+	addSurfaceForms(forms)
+	addCorpusSentences(sentences)
+
+	var entities map[string]map[string]int
+	if config.GenerateEntities {
+		entities = findEntities(doc)
+		addEntities(entities)
+	}
+
+	if config.CacheTokenization {
+		tokenCache[cacheKey] = FileTokens{CategorizedWords: categorizedWords, AllWords: allWords, ProperNounPhrases: phrases, SurfaceForms: forms, Sentences: sentences, Entities: entities}
+	}
+
+	return categorizedWords, allWords, nil
+}
+
+// Indexes of the tokens containing a letter. Punctuation and number tokens can't
+// hold a counted word, so processFile skips them.
+func wordTokenIndexes(tokens []prose.Token) []int {
+	var indexes []int
+	for i, tok := range tokens {
+		if strings.IndexFunc(tok.Text, unicode.IsLetter) >= 0 {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// Get all files of a supported type (see inputReaders) from the input directory,
+// including its subdirectories when recursive is on
+func listInputFiles(inputDir string, outputDir string) ([]string, error) {
+	var inputFiles []string
+	if config.Recursive {
+		var err error
+		inputFiles, err = walkInputFiles(inputDir, outputDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input directory: %v", err)
+		}
+	} else {
+		files, err := ioutil.ReadDir(inputDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input directory: %v", err)
+		}
+		for _, file := range files {
+			if _, supported := inputReaderFor(file.Name()); !file.IsDir() && supported {
+				inputFiles = append(inputFiles, filepath.Join(inputDir, file.Name()))
+			}
+		}
+	}
+
+	if len(inputFiles) == 0 {
+		return nil, fmt.Errorf("no supported input files found in input directory")
+	}
+	return inputFiles, nil
+}
+
+// Output directory for an input directory: the -output flag, then the
+// outputDirectory setting, or by default a directory named after the input directory
+func resolveOutputDir(inputDir string, outputDir string) string {
+	if outputDir != "" {
+		return outputDir
+	}
+	if config.OutputDirectory != "" {
+		return config.OutputDirectory
+	}
+	return filepath.Base(inputDir) + "_ewClassifiers"
+}
+
+// Process all files in the input directory and write the output files
+func processAllFiles(ctx context.Context, inputDir string, outputDir string) (*Result, error) {
+	// Create output directory, by default named after the input directory
+	outputDir = resolveOutputDir(inputDir, outputDir)
+	if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %v", err)
+	}
+	// Fail now rather than after the lookups when nothing can be written there
+	if err := checkWritable(outputDir); err != nil {
+		return nil, fmt.Errorf("output directory %s is not writable: %v", outputDir, err)
+	}
+
+	manifestEntries = nil
+	skippedOutputFiles = 0
+	properNounPhrases = make(map[string]int)
+	surfaceForms = make(map[string]map[string]int)
+	corpusSentences = make(map[string][]string)
+	namedEntities = make(map[string]map[string]int)
+	startedAt := time.Now()
+	resetRunStats()
+
+	inputFiles, err := listInputFiles(inputDir, outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	Statusf("Found %d input files to process\n", len(inputFiles))
+
+	// Initialize maps to collect words from all files
+	allCategorizedWords := make(map[string][]string)
+	for _, category := range categoryNames {
+		allCategorizedWords[category] = []string{}
+	}
+	allWordsDict := make(map[string]int)
+
+	// Input files each word occurred in, for WordSources.txt
+	wordSources := make(map[string]map[string]bool)
+
+	// Fail once with a clear message instead of failing every file the same way
+	if err := checkProseModels(); err != nil {
+		return nil, fmt.Errorf("failed to initialize the prose NLP models, no files can be classified: %v "+
+			"(make sure github.com/jdkato/prose/v2 and its bundled model data are installed correctly)", err)
+	}
+	included, excluded := wordFilterSet(includeWordList), wordFilterSet(excludeWordList)
+
+	// Per-file part-of-speech counts for POSDistribution.csv
+	var posDistribution []POSCounts
+
+	// Optionally look words up while the remaining files are still being tokenized
+	var pipelineWords chan<- string
+	var pipelineDone <-chan struct{}
+	queuedWords := make(map[string]bool)
+	if config.PipelineLookups {
+		pipelineWords, pipelineDone = startLookupPipeline(ctx)
+	}
+
+	// Process each file
+	var processedFiles []string
+	for _, inputFile := range inputFiles {
+		if ctx.Err() != nil {
+			return nil, interruptedError(ctx)
+		}
+		Statusf("Processing file: %s\n", inputFile)
+
+		parseStarted := time.Now()
+		categorizedWords, fileWords, err := processFile(inputFile)
+		addParseTime(time.Since(parseStarted))
+		if err != nil {
+			Statusf("Warning: skipping file %s: %v\n", inputFile, err)
+			continue
+		}
+		processedFiles = append(processedFiles, inputFile)
+
+		posDistribution = append(posDistribution, countPOS(inputFile, categorizedWords))
+
+		// Merge words into collection
+		for category, words := range categorizedWords {
+			allCategorizedWords[category] = append(allCategorizedWords[category], words...)
+		}
+
+		if config.GenerateWordSources {
+			addWordSources(wordSources, inputDir, inputFile, fileWords)
+		}
+
+		lookupWords := lookupWordSet(categorizedWords)
+		for word, count := range fileWords {
+			allWordsDict[word] += count
+
+			if config.PipelineLookups && lookupWords[word] && !queuedWords[word] && !excluded[word] {
+				queuedWords[word] = true
+				pipelineWords <- word
+			}
+		}
+
+		Statusf("Finished processing file: %s\n", inputFile)
+	}
+
+	if config.PipelineLookups {
+		// Frequency ordering needs every word, so wait for the lookups to catch up
+		close(pipelineWords)
+		<-pipelineDone
+	}
+
+	if config.CacheTokenization {
+		saveTokenCache()
+	}
+
+	if config.GeneratePOSDistribution {
+		posDistributionPath := filepath.Join(outputDir, "POSDistribution.csv")
+		if err := writePOSDistribution(posDistributionPath, inputDir, posDistribution); err != nil {
+			return nil, fmt.Errorf("failed to create POSDistribution.csv file: %v", err)
+		}
+		recordOutputFile(posDistributionPath, "POSDistribution", "csv", len(posDistribution))
+		Statusln("- POSDistribution.csv complete")
+	}
+
+	// Report coverage of the reference word list against everything found in the corpus
+	if config.CoverageWordList != "" {
+		coveragePath := filepath.Join(outputDir, "Coverage.txt")
+		referenceCount, err := writeCoverageReport(coveragePath, config.CoverageWordList, allWordsDict)
+		if err != nil {
+			Statusf("Error writing coverage report: %v\n", err)
+		} else {
+			recordOutputFile(coveragePath, "Coverage", "report", referenceCount)
+			Statusln("- Coverage.txt complete")
+		}
+	}
+
+	// Drop the excluded words before anything else is looked up
+	if len(excluded) > 0 {
+		allCategorizedWords, allWordsDict = removeExcludedWords(allCategorizedWords, allWordsDict, excluded)
+	}
+
+	// Look compounds up as a unit and fall back to their parts, before the thresholds apply to both
+	if config.HyphenatedWords == "keep" && config.SplitUnknownCompounds {
+		allCategorizedWords, allWordsDict = splitUnknownCompounds(ctx, allCategorizedWords, allWordsDict)
+		if ctx.Err() != nil {
+			return nil, interruptedError(ctx)
+		}
+	}
+
+	// Read force_include.txt now so the thresholds keep forced words found in the corpus
+	forcedWordList := loadForcedWords()
+
+	// Drop short and rare words before anything is looked up
+	if config.MinWordLength > 1 || config.MinFrequency > 1 {
+		allCategorizedWords, allWordsDict = filterByThresholds(allCategorizedWords, allWordsDict, withForcedWords(included, forcedWordList))
+		log.Printf("Keeping %d words with at least %d characters and %d occurrences\n",
+			len(allWordsDict), config.MinWordLength, config.MinFrequency)
+	}
+
+	// Group by dictionary part of speech instead of the context-dependent prose tag
+	if config.CategorizeBy == "dictionary" {
+		prefetchWordDetails(ctx, sortByFrequency(allWordsDict))
+		allCategorizedWords = recategorizeByDictionary(ctx, allCategorizedWords)
+		if ctx.Err() != nil {
+			return nil, interruptedError(ctx)
+		}
+	}
+
+	if config.ExclusiveCategories {
+		allCategorizedWords = assignPrimaryCategories(allCategorizedWords)
+	}
+
+	// Add mandatory vocabulary from force_include.txt
+	forcedWords, missingForcedWords := addForcedWords(forcedWordList, allCategorizedWords, allWordsDict)
+	keptWords := append(includedWordsIn(allWordsDict, included), forcedWords...)
+
+	// Focus on the configured frequency rank window
+	if config.MinRank > 0 || config.MaxRank > 0 {
+		allCategorizedWords, allWordsDict = filterByRank(allCategorizedWords, allWordsDict, keptWords)
+		log.Printf("Keeping %d words within frequency ranks %d-%d\n", len(allWordsDict), config.MinRank, config.MaxRank)
+	}
+
+	// Keep only the most frequent words of each category
+	if config.MaxWordsPerCategory > 0 {
+		allCategorizedWords, allWordsDict = capWordsPerCategory(allCategorizedWords, allWordsDict, keptWords)
+		log.Printf("Keeping at most %d words per category (%d words)\n", config.MaxWordsPerCategory, len(allWordsDict))
+	}
+
+	Statusln("\nProcessing complete. Starting dictionary lookups...")
+
+	// Define output file paths
+	outputFiles := make(map[string]string)
+	for _, category := range categoryNames {
+		outputFiles[category] = filepath.Join(outputDir, category+".txt")
+	}
+
+	explanationFiles := map[string]string{}
+	if config.GenerateExplanations {
+		// Only setup explanation files if the toggle is enabled
+		for category, file := range outputFiles {
+			explanationFiles[category] = strings.Replace(file, ".txt", "_ex.txt", 1)
+		}
+	}
+
+	exampleSentencesFiles := map[string]string{}
+	if config.GenerateExampleSentences {
+		// Only setup example sentences files if the toggle is enabled
+		for category, file := range outputFiles {
+			exampleSentencesFiles[category] = strings.Replace(file, ".txt", "_es.txt", 1)
+		}
+	}
+
+	// Create a file for unknown words
+	unknownWordsPath := filepath.Join(outputDir, "UnknownWords.txt")
+	unknownWordsFile, err := createOutputFile(unknownWordsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create UnknownWords.txt file: %v", err)
+	}
+	defer unknownWordsFile.Close()
+	unknownWordsWriter := bufio.NewWriter(unknownWordsFile)
+
+	// Get all unique words and sort by frequency, scaled by category weight if configured
+	sortedAllWords := sortByFrequency(allWordsDict)
+	if len(config.CategoryWeights) > 0 {
+		sortedAllWords = sortByWeightedFrequency(allWordsDict, allCategorizedWords)
+	}
+
+	// Fetch uncached words in parallel; the category files below are written in order from the cache.
+	// Words only in categories that skip lookups are left out.
+	lookupWords := lookupWordSet(allCategorizedWords)
+	var prefetchWords []string
+	for _, word := range sortedAllWords {
+		if lookupWords[word] {
+			prefetchWords = append(prefetchWords, word)
+		}
+	}
+	prefetchWordDetails(ctx, prefetchWords)
+	if ctx.Err() != nil {
+		return nil, interruptedError(ctx)
+	}
+
+	// Track unknown words
+	var unknownWords []string
+
+	// Track the known words written for each category
+	knownWordsByCategory := make(map[string][]string)
+
+	// Record progress so an interrupted run can continue with -resume
+	progress, err := startResumeState(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", resumeStateName, err)
+	}
+	defer progress.close()
+
+	// Write each category to separate files
+	for category, words := range allCategorizedWords {
+		// Create word frequency map and sort
+		freqMap := countFrequencies(words)
+		sortedWords := sortByFrequency(freqMap)
+
+		if len(sortedWords) == 0 {
+			log.Printf("No words in category: %s\n", category)
+			continue
+		}
+
+		if config.GenerateWordCloud {
+			wordCloudPath := filepath.Join(outputDir, category+"_wc"+wordCloudExtension())
+			if err := writeWordCloud(wordCloudPath, freqMap); err != nil {
+				log.Printf("Error writing word cloud for %s: %v\n", category, err)
+			} else {
+				recordOutputFile(wordCloudPath, category, "wordcloud", len(buildWordCloud(freqMap)))
+			}
+		}
+
+		filePath := outputFiles[category]
+
+		// Create word list file (always created)
+		wordFile, err := openCategoryFile(filePath, progress, category)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create output file for %s: %v", category, err)
+		}
+		defer wordFile.Close()
+		wordWriter := bufio.NewWriter(wordFile)
+
+		// Categories that skip lookups only get their word list
+		writeExplanations := config.GenerateExplanations && !skipLookup[category]
+		writeExamples := config.GenerateExampleSentences && !skipLookup[category]
+
+		// Only create explanation file if the toggle is enabled
+		var exFile *outputFile
+		var exWriter *bufio.Writer
+		if writeExplanations {
+			exFilePath := explanationFiles[category]
+			exFile, err = openCategoryFile(exFilePath, progress, category)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create explanation file for %s: %v", category, err)
+			}
+			defer exFile.Close()
+			exWriter = bufio.NewWriter(exFile)
+		}
+
+		// Only create example sentences file if the toggle is enabled
+		var esFile *outputFile
+		var esWriter *bufio.Writer
+		if writeExamples {
+			esFilePath := exampleSentencesFiles[category]
+			esFile, err = openCategoryFile(esFilePath, progress, category)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create example sentences file for %s: %v", category, err)
+			}
+			defer esFile.Close()
+			esWriter = bufio.NewWriter(esFile)
+		}
+
+		Statusf("\nProcessing %s category (%d words):\n", category, len(sortedWords))
+
+		// Deduplicate the words
+		sortedWords = deduplicateStrings(sortedWords)
+
+		// Process each word, starting with those an interrupted run already wrote
+		var knownWords []string
+		exampleCount := 0
+		for _, word := range progress.written(category) {
+			status, _ := progress.done(category, word)
+			if status == resumeUnknown {
+				unknownWords = append(unknownWords, capitalizePhrase(word))
+				continue
+			}
+			knownWords = append(knownWords, word)
+			if status == resumeExample {
+				exampleCount++
+			}
+		}
+		for i, word := range sortedWords {
+			if ctx.Err() != nil {
+				break
+			}
+			if _, written := progress.done(category, word); written {
+				continue
+			}
+			if skipLookup[category] {
+				wordWriter.WriteString(formatListEntry(word, freqMap[word]))
+				knownWords = append(knownWords, word)
+				if progress != nil {
+					wordWriter.Flush()
+					progress.record(category, word, resumeKnown)
+				}
+				continue
+			}
+			reportProgress(
+				fmt.Sprintf("Dictionary lookup (%s)", category),
+				word,
+				i+1,
+				len(sortedWords))
+
+			// Fetch word details and check if it's unknown. A failed lookup is listed with
+			// the unknown words for this run but stays out of word_unknown.json.
+			wordDetails, err := fetchWordDetails(ctx, word, category)
+			if err != nil && ctx.Err() != nil {
+				// Cancelled mid-lookup: leave the word unrecorded so -resume redoes it
+				break
+			}
+			isUnknown := err != nil
+			var lookupErr *LookupError
+			failedLookup := errors.As(err, &lookupErr)
+
+			status := resumeKnown
+			if isUnknown {
+				// Add to unknown words list
+				unknownWords = append(unknownWords, capitalizePhrase(word))
+				status = resumeUnknown
+			} else {
+				// Only write known words to the word list file
+				wordWriter.WriteString(formatListEntry(word, freqMap[word]))
+				knownWords = append(knownWords, word)
+
+				// Only write to explanation file if toggle is enabled
+				if writeExplanations {
+					exWriter.WriteString(formatExplanation(word, category, wordDetails))
+				}
+
+				// Only write to example sentences file if toggle is enabled
+				if writeExamples {
+					esContent := generateExampleSentencesContent(word)
+					if esContent != "" {
+						esWriter.WriteString(esContent)
+						exampleCount++
+						status = resumeExample
+					}
+				}
+			}
+
+			// Flush the word's lines before recording it as written. A failed lookup
+			// stays unrecorded so -resume looks the word up again.
+			if progress != nil && !failedLookup {
+				wordWriter.Flush()
+				if writeExplanations {
+					exWriter.Flush()
+				}
+				if writeExamples {
+					esWriter.Flush()
+				}
+				progress.record(category, word, status)
+			}
+		}
+
+		wordWriter.Flush()
+		if config.IncludeHeaders {
+			wordFile.countHeader = category
+		}
+		wordFile.Close()
+		recordOutputFile(filePath, category, "list", len(knownWords))
+		if writeExplanations {
+			exWriter.Flush()
+			exFile.Close()
+			recordOutputFile(explanationFiles[category], category, "explanations", len(knownWords))
+		}
+		if writeExamples {
+			esWriter.Flush()
+			esFile.Close()
+			recordOutputFile(exampleSentencesFiles[category], category, "examples", exampleCount)
+		}
+
+		// Anki, CSV, JSON and the quiz need definitions, so they only cover looked-up words
+		if !skipLookup[category] {
+			knownWordsByCategory[category] = knownWords
+		}
+
+		if config.GenerateSyllableTiers {
+			tiersPath := filepath.Join(outputDir, category+"_syllables.txt")
+			if err := writeSyllableTiers(tiersPath, knownWords); err != nil {
+				return nil, fmt.Errorf("failed to create syllable tiers file for %s: %v", category, err)
+			}
+			recordOutputFile(tiersPath, category, "syllable-tiers", len(knownWords))
+		}
+
+		// The files of this category are flushed and progress recorded, so stop here
+		if ctx.Err() != nil {
+			return nil, interruptedError(ctx)
+		}
+
+		if config.IncludeAudio {
+			audioPath := filepath.Join(outputDir, category+"_audio.txt")
+			count, err := writeAudioList(audioPath, knownWords)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create audio file for %s: %v", category, err)
+			}
+			recordOutputFile(audioPath, category, "audio", count)
+		}
+
+		Statusf("\n- Category '%s' processed: %d words\n", category, len(sortedWords))
+	}
+
+	Statusln("\nGenerating final outputs...")
+
+	// Always create AllWords.txt file
+	allWordsPath := filepath.Join(outputDir, "AllWords.txt")
+	allWordsFile, err := createOutputFile(allWordsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AllWords.txt file: %v", err)
+	}
+	defer allWordsFile.Close()
+	allWordsWriter := bufio.NewWriter(allWordsFile)
+
+	// Only create AllWords_ex.txt if toggle is enabled
+	var allWordsExFile *outputFile
+	var allWordsExWriter *bufio.Writer
+	if config.GenerateExplanations {
+		allWordsExPath := filepath.Join(outputDir, "AllWords_ex.txt")
+		allWordsExFile, err = createOutputFile(allWordsExPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create AllWords_ex.txt file: %v", err)
+		}
+		defer allWordsExFile.Close()
+		allWordsExWriter = bufio.NewWriter(allWordsExFile)
+	}
+
+	// Only create AllWords_es.txt if toggle is enabled
+	var allWordsEsFile *outputFile
+	var allWordsEsWriter *bufio.Writer
+	if config.GenerateExampleSentences {
+		allWordsEsPath := filepath.Join(outputDir, "AllWords_es.txt")
+		allWordsEsFile, err = createOutputFile(allWordsEsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create AllWords_es.txt file: %v", err)
+		}
+		defer allWordsEsFile.Close()
+		allWordsEsWriter = bufio.NewWriter(allWordsEsFile)
+	}
+
+	// Deduplicate unknown words list
+	unknownWords = deduplicateStrings(unknownWords)
+
+	// Write unknown words to UnknownWords.txt
+	for _, word := range unknownWords {
+		unknownWordsWriter.WriteString(word + "\n")
+	}
+	unknownWordsWriter.Flush()
+	unknownWordsFile.Close()
+	recordOutputFile(unknownWordsPath, "UnknownWords", "list", len(unknownWords))
+
+	// Process all words
+	sortedAllWords = deduplicateStrings(sortedAllWords)
+	var allKnownWords []string
+	allExampleCount := 0
+	for i, word := range sortedAllWords {
+		reportProgress("Processing All Words", word, i+1, len(sortedAllWords))
+
+		// Skip unknown words in AllWords.txt and related files
+		if !hasWordDetails(word) {
+			continue
+		}
+		if config.MaxAllWords > 0 && len(allKnownWords) >= config.MaxAllWords {
+			break
+		}
+
+		allWordsWriter.WriteString(formatListEntry(word, allWordsDict[word]))
+		allKnownWords = append(allKnownWords, word)
+
+		if config.GenerateExplanations {
+			wordDetails, _ := fetchWordDetails(ctx, word, "AllWords")
+			allWordsExWriter.WriteString(formatExplanation(word, "AllWords", wordDetails))
+		}
+
+		if config.GenerateExampleSentences {
+			esContent := generateExampleSentencesContent(word)
+			if esContent != "" {
+				allWordsEsWriter.WriteString(esContent)
+				allExampleCount++
+			}
+		}
+	}
+
+	allWordsWriter.Flush()
+	if config.IncludeHeaders {
+		allWordsFile.countHeader = "AllWords"
+	}
+	allWordsFile.Close()
+	recordOutputFile(allWordsPath, "AllWords", "list", len(allKnownWords))
+
+	if config.GenerateExplanations {
+		allWordsExWriter.Flush()
+		allWordsExFile.Close()
+		recordOutputFile(filepath.Join(outputDir, "AllWords_ex.txt"), "AllWords", "explanations", len(allKnownWords))
+		Statusln("- AllWords_ex.txt complete")
+	}
+
+	if config.GenerateExampleSentences {
+		allWordsEsWriter.Flush()
+		allWordsEsFile.Close()
+		recordOutputFile(filepath.Join(outputDir, "AllWords_es.txt"), "AllWords", "examples", allExampleCount)
+		Statusln("- AllWords_es.txt complete")
+	}
+
+	Statusln("- AllWords.txt complete")
+	Statusln("- UnknownWords.txt complete")
+
+	if config.GenerateSuggestions {
+		suggestionsPath := filepath.Join(outputDir, "UnknownSuggestions.txt")
+		suggested, err := writeUnknownSuggestions(suggestionsPath, unknownWords)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create UnknownSuggestions.txt file: %v", err)
+		}
+		recordOutputFile(suggestionsPath, "UnknownWords", "suggestions", suggested)
+		Statusln("- UnknownSuggestions.txt complete")
+	}
+
+	if config.GenerateAnki {
+		ankiPath := filepath.Join(outputDir, "Anki.txt")
+		cards, err := writeAnkiDeck(ctx, ankiPath, knownWordsByCategory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Anki.txt file: %v", err)
+		}
+		recordOutputFile(ankiPath, "AllWords", "anki", cards)
+		Statusln("- Anki.txt complete")
+	}
+
+	if config.GenerateAnkiAudioDeck {
+		cards, err := writeAnkiAudioDeck(ctx, outputDir, knownWordsByCategory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create AnkiDeck.txt file: %v", err)
+		}
+		recordOutputFile(filepath.Join(outputDir, "AnkiDeck.txt"), "AllWords", "anki", cards)
+		Statusln("\n- AnkiDeck.txt complete")
+	}
+
+	if config.GenerateParadigms {
+		paradigmsPath := filepath.Join(outputDir, "Paradigms.txt")
+		verbs, nouns := knownWordsByCategory["Verbs"], knownWordsByCategory["Nouns"]
+		if err := writeParadigms(paradigmsPath, verbs, nouns); err != nil {
+			return nil, fmt.Errorf("failed to create Paradigms.txt file: %v", err)
+		}
+		recordOutputFile(paradigmsPath, "AllWords", "paradigms", len(verbs)+len(nouns))
+		Statusln("- Paradigms.txt complete")
+	}
+
+	if config.GenerateSpeechScript {
+		speechScriptPath := filepath.Join(outputDir, "SpeechScript.txt")
+		if err := writeSpeechScript(speechScriptPath, allKnownWords); err != nil {
+			return nil, fmt.Errorf("failed to create SpeechScript.txt file: %v", err)
+		}
+		recordOutputFile(speechScriptPath, "AllWords", "speech-script", len(allKnownWords))
+		Statusln("- SpeechScript.txt complete")
+	}
+
+	if config.GenerateReverseIndex {
+		reverseIndexPath := filepath.Join(outputDir, "ReverseIndex.txt")
+		entries, err := writeReverseIndex(reverseIndexPath, allKnownWords)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ReverseIndex.txt file: %v", err)
+		}
+		recordOutputFile(reverseIndexPath, "AllWords", "reverse-index", entries)
+		Statusln("- ReverseIndex.txt complete")
+	}
+
+	if config.GenerateCSV {
+		csvPath := filepath.Join(outputDir, "Words.csv")
+		words, err := writeWordsCSV(csvPath, knownWordsByCategory, allWordsDict)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Words.csv file: %v", err)
+		}
+		recordOutputFile(csvPath, "AllWords", "csv", words)
+		Statusln("- Words.csv complete")
+	}
+
+	if config.GenerateJSON {
+		resultsPath := filepath.Join(outputDir, "results.json")
+		words, err := writeJSONResults(resultsPath, knownWordsByCategory, allWordsDict, unknownWords, len(processedFiles))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create results.json file: %v", err)
+		}
+		recordOutputFile(resultsPath, "AllWords", "json", words)
+		Statusln("- results.json complete")
+	}
+
+	if config.GenerateCombined {
+		reportPath := filepath.Join(outputDir, combinedReportName())
+		words, err := writeCombinedReport(ctx, reportPath, knownWordsByCategory, allWordsDict)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s file: %v", combinedReportName(), err)
+		}
+		recordOutputFile(reportPath, "AllWords", "report", words)
+		Statusf("- %s complete\n", combinedReportName())
+	}
+
+	if config.GenerateQuiz {
+		quizPath := filepath.Join(outputDir, "Quiz.txt")
+		questions, err := writeQuiz(quizPath, filepath.Join(outputDir, "QuizAnswers.txt"), knownWordsByCategory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Quiz.txt file: %v", err)
+		}
+		recordOutputFile(quizPath, "AllWords", "quiz", questions)
+		recordOutputFile(filepath.Join(outputDir, "QuizAnswers.txt"), "AllWords", "quiz-answers", questions)
+		Statusln("- Quiz.txt complete")
+	}
+
+	if len(forcedWords) > 0 {
+		forcedWordsPath := filepath.Join(outputDir, "ForcedWords.txt")
+		if err := writeForcedWordsReport(forcedWordsPath, forcedWords, missingForcedWords, allWordsDict); err != nil {
+			return nil, fmt.Errorf("failed to create ForcedWords.txt file: %v", err)
+		}
+		recordOutputFile(forcedWordsPath, "ForcedWords", "report", len(forcedWords))
+		Statusln("- ForcedWords.txt complete")
+	}
+
+	if config.GenerateWordCloud {
+		allWordsFreq := make(map[string]int)
+		for word, count := range allWordsDict {
+			allWordsFreq[capitalizePhrase(word)] += count
+		}
+		wordCloudPath := filepath.Join(outputDir, "WordCloud"+wordCloudExtension())
+		if err := writeWordCloud(wordCloudPath, allWordsFreq); err != nil {
+			return nil, fmt.Errorf("failed to create word cloud file: %v", err)
+		}
+		recordOutputFile(wordCloudPath, "AllWords", "wordcloud", len(buildWordCloud(allWordsFreq)))
+		Statusln("- WordCloud" + wordCloudExtension() + " complete")
+	}
+
+	if config.GenerateSurfaceForms {
+		surfaceFormsPath := filepath.Join(outputDir, "SurfaceForms.txt")
+		listed, err := writeSurfaceForms(surfaceFormsPath, allWordsDict)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SurfaceForms.txt file: %v", err)
+		}
+		recordOutputFile(surfaceFormsPath, "SurfaceForms", "list", listed)
+		Statusln("- SurfaceForms.txt complete")
+	}
+
+	if config.GenerateProperNounPhrases {
+		phrasesPath := filepath.Join(outputDir, "ProperNounPhrases.txt")
+		if err := writeProperNounPhrases(phrasesPath, properNounPhrases); err != nil {
+			return nil, fmt.Errorf("failed to create ProperNounPhrases.txt file: %v", err)
+		}
+		recordOutputFile(phrasesPath, "ProperNounPhrases", "list", len(properNounPhrases))
+		Statusln("- ProperNounPhrases.txt complete")
+	}
+
+	if config.GenerateEntities {
+		entitiesPath := filepath.Join(outputDir, "Entities.txt")
+		listed, err := writeEntities(entitiesPath, namedEntities)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Entities.txt file: %v", err)
+		}
+		recordOutputFile(entitiesPath, "Entities", "list", listed)
+		Statusln("- Entities.txt complete")
+	}
+
+	if config.GenerateWordSources {
+		wordSourcesPath := filepath.Join(outputDir, "WordSources.txt")
+		listed, err := writeWordSources(wordSourcesPath, allWordsDict, wordSources)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create WordSources.txt file: %v", err)
+		}
+		recordOutputFile(wordSourcesPath, "WordSources", "list", listed)
+		Statusln("- WordSources.txt complete")
+	}
+
+	if config.GenerateRunMetadata {
+		if err := writeRunMetadata(outputDir, inputDir, startedAt, processedFiles); err != nil {
+			return nil, fmt.Errorf("failed to create run_metadata.json file: %v", err)
+		}
+		Statusln("- run_metadata.json complete")
+	}
+
+	// Write the manifest last so it covers every generated file
+	if config.GenerateManifest {
+		if err := writeManifest(outputDir); err != nil {
+			return nil, fmt.Errorf("failed to create manifest.json file: %v", err)
+		}
+		Statusln("- manifest.json complete")
+	}
+
+	// Hand the finished output over to the user's own scripts
+	runPostRunCommands(outputDir)
+
+	// Report results
+	Statusf("\n===== Analysis Results =====\n")
+	Statusf("Results written to directory: %s\n", outputDir)
+	if config.GenerateExplanations {
+		Statusf("Word explanation files were generated.\n")
+	} else {
+		Statusf("Word explanation files were not generated (disabled in config).\n")
+	}
+	if config.GenerateExampleSentences {
+		Statusf("Example sentences files were generated.\n")
+	} else {
+		Statusf("Example sentences files were not generated (disabled in config).\n")
+	}
+	if config.SkipUnchangedOutputs {
+		Statusf("Unchanged output files skipped: %d\n", skippedOutputFiles)
+	}
+	stats := finishRunStats(allWordsDict, startedAt)
+
+	// Outputs after the categories may have stopped early; keep the progress so -resume redoes them
+	if ctx.Err() != nil {
+		return nil, interruptedError(ctx)
+	}
+
+	progress.finish()
+	return &Result{
+		OutputDir:    outputDir,
+		Files:        processedFiles,
+		Categories:   knownWordsByCategory,
+		UnknownWords: unknownWords,
+		Frequencies:  allWordsDict,
+		Stats:        stats,
+	}, nil
+}
@@ -0,0 +1,205 @@
+package classifier
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// A definition that happens to contain the old "No details available." marker
+// must not turn a known word into an unknown one
+func TestDefinitionWithFilterPhraseIsKnown(t *testing.T) {
+	savedCache, savedUnknown := wordCache, wordUnknown
+	defer func() { wordCache, wordUnknown = savedCache, savedUnknown }()
+
+	wordCache = map[string]WordCache{
+		"nihil": {Definitions: []Definition{{
+			PartOfSpeech: "noun",
+			Definition:   "Means no details available in older usage.",
+		}}},
+	}
+	wordUnknown = map[string]unknownEntry{}
+
+	if !hasWordDetails("Nihil") {
+		t.Fatal("hasWordDetails reported a cached word with definitions as unknown")
+	}
+
+	details, err := fetchWordDetails(context.Background(), "nihil", "Nouns")
+	if err != nil {
+		t.Fatalf("fetchWordDetails returned an error for a known word: %v", err)
+	}
+	if !strings.Contains(details, "no details available in older usage") {
+		t.Errorf("definition missing from the details:\n%s", details)
+	}
+}
+
+// Words without definitions or marked unknown have no details
+func TestHasWordDetailsUnknown(t *testing.T) {
+	savedCache, savedUnknown := wordCache, wordUnknown
+	defer func() { wordCache, wordUnknown = savedCache, savedUnknown }()
+
+	wordCache = map[string]WordCache{
+		"empty":  {},
+		"marked": {Definitions: []Definition{{Definition: "A definition."}}},
+	}
+	wordUnknown = map[string]unknownEntry{"marked": {}}
+
+	for _, word := range []string{"empty", "marked", "missing"} {
+		if hasWordDetails(word) {
+			t.Errorf("hasWordDetails(%q) = true, want false", word)
+		}
+	}
+}
+
+// Explanation lines for a cached word with the given config changes applied
+func explanationLines(t *testing.T, data WordCache, configure func(*OutputConfig)) []string {
+	t.Helper()
+	savedConfig, savedCache := config, wordCache
+	defer func() { config, wordCache = savedConfig, savedCache }()
+
+	config = OutputConfig{IncludeSynonyms: true}
+	configure(&config)
+	wordCache = map[string]WordCache{"fox": data}
+
+	details, err := fetchWordDetails(context.Background(), "fox", "Nouns")
+	if err != nil {
+		t.Fatalf("fetchWordDetails: %v", err)
+	}
+	return strings.Split(details, "\n")
+}
+
+func assertLines(t *testing.T, got []string, want []string) {
+	t.Helper()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got lines %q, want %q", got, want)
+	}
+}
+
+var foxWithExamples = WordCache{Definitions: []Definition{
+	{PartOfSpeech: "noun", Definition: "A wild canine.", Example: "The fox ran.", Synonyms: []string{"reynard", "vixen"}},
+	{PartOfSpeech: "noun", Definition: "A cunning person."},
+	{PartOfSpeech: "verb", Definition: "To trick.", Example: "He foxed them."},
+}}
+
+func TestExplanationIndentationWithTabs(t *testing.T) {
+	lines := explanationLines(t, foxWithExamples, func(*OutputConfig) {})
+	assertLines(t, lines, []string{
+		"Fox",
+		"\tFox 1, noun: A wild canine.",
+		"\t\tFox 1 Example: The fox ran.",
+		"\t\tFox 1 Synonyms: reynard, vixen",
+		"\tFox 2, noun: A cunning person.",
+		"\tFox 3, verb: To trick.",
+		"\t\tFox 3 Example: He foxed them.",
+	})
+}
+
+func TestExplanationIndentationWithSpaces(t *testing.T) {
+	lines := explanationLines(t, foxWithExamples, func(c *OutputConfig) {
+		c.IndentStyle, c.IndentSize = "spaces", 2
+	})
+	assertLines(t, lines, []string{
+		"Fox",
+		"  Fox 1, noun: A wild canine.",
+		"    Fox 1 Example: The fox ran.",
+		"    Fox 1 Synonyms: reynard, vixen",
+		"  Fox 2, noun: A cunning person.",
+		"  Fox 3, verb: To trick.",
+		"    Fox 3 Example: He foxed them.",
+	})
+}
+
+func TestExplanationIndentationWithoutExamplesOrSynonyms(t *testing.T) {
+	plain := WordCache{Definitions: []Definition{
+		{PartOfSpeech: "noun", Definition: "A wild canine.", Synonyms: []string{"vixen"}},
+		{PartOfSpeech: "verb", Definition: "To trick."},
+	}}
+	lines := explanationLines(t, plain, func(c *OutputConfig) { c.IncludeSynonyms = false })
+	assertLines(t, lines, []string{
+		"Fox",
+		"\tFox 1, noun: A wild canine.",
+		"\tFox 2, verb: To trick.",
+	})
+}
+
+// Definitions filtered out leave no indented lines behind and no gaps in the numbering
+func TestExplanationIndentationAfterFiltering(t *testing.T) {
+	lines := explanationLines(t, foxWithExamples, func(c *OutputConfig) { c.FilterNoExample = true })
+	assertLines(t, lines, []string{
+		"Fox",
+		"\tFox 1, noun: A wild canine.",
+		"\t\tFox 1 Example: The fox ran.",
+		"\t\tFox 1 Synonyms: reynard, vixen",
+		"\tFox 2, verb: To trick.",
+		"\t\tFox 2 Example: He foxed them.",
+	})
+}
+
+func TestCapitalizeSentence(t *testing.T) {
+	saved := config
+	defer func() { config = saved }()
+	config = OutputConfig{}
+
+	tests := []struct {
+		sentence string
+		want     string
+	}{
+		{"", ""},
+		{"the fox ran.", "The fox ran."},
+		{"The fox ran.", "The fox ran."},
+		{"3 foxes ran.", "3 foxes ran."},
+		{"1990s fashion returned.", "1990s fashion returned."},
+		{"ebay sold the fox.", "ebay sold the fox."},
+		{"iPhone sales rose.", "iPhone sales rose."},
+		{"\"the fox ran,\" she said.", "\"The fox ran,\" she said."},
+		{"“where is it?” he asked.", "“Where is it?” he asked."},
+		{"'tis the season.", "'Tis the season."},
+		{"(the fox) ran.", "(The fox) ran."},
+		{"\"iphone\" was the word.", "\"iphone\" was the word."},
+		{"\"\"", "\"\""},
+		{"éclairs were served.", "Éclairs were served."},
+	}
+	for _, test := range tests {
+		if got := capitalizeSentence(test.sentence); got != test.want {
+			t.Errorf("capitalizeSentence(%q) = %q, want %q", test.sentence, got, test.want)
+		}
+	}
+}
+
+func TestCapitalizeSentenceModes(t *testing.T) {
+	saved := config
+	defer func() { config = saved }()
+
+	config.ExampleCapitalization = "none"
+	if got := capitalizeSentence("the fox ran."); got != "the fox ran." {
+		t.Errorf("none: got %q", got)
+	}
+	config.ExampleCapitalization = "always"
+	if got := capitalizeSentence("ebay sold it."); got != "Ebay sold it." {
+		t.Errorf("always: got %q", got)
+	}
+}
+
+func TestSplitSlashSeparatedWords(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"and/or", []string{"and", "or"}},
+		{"word", []string{"word"}},
+		{"/word", []string{"word"}},
+		{"word/", []string{"word"}},
+		{"a//b", []string{"a", "b"}},
+		{"/a//b/", []string{"a", "b"}},
+		{"a/ /b", []string{"a", "b"}},
+		{"/", nil},
+		{"//", nil},
+		{"", nil},
+	}
+	for _, test := range tests {
+		got := splitSlashSeparatedWords(test.text)
+		if strings.Join(got, "|") != strings.Join(test.want, "|") || len(got) != len(test.want) {
+			t.Errorf("splitSlashSeparatedWords(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
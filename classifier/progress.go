@@ -0,0 +1,41 @@
+package classifier
+
+// ProgressFunc receives the progress of a run: the stage, such as "Dictionary
+// lookup", the word or text being handled and its position among total items.
+// Lookups run concurrently, but calls are never made at the same time.
+type ProgressFunc func(stage string, item string, current, total int)
+
+// The embedder's progress callback; nil prints progress lines to Console
+var progressHandler ProgressFunc
+
+// WithProgress reports progress to fn instead of printing a progress line to Console
+func WithProgress(fn ProgressFunc) Option {
+	return func(o *options) { o.progress = fn }
+}
+
+// SetProgress reports the progress of later runs to fn; nil restores the
+// progress line on Console
+func (c *Classifier) SetProgress(fn ProgressFunc) {
+	progressHandler = fn
+}
+
+func reportProgress(stage string, item string, current, total int) {
+	if progressHandler != nil {
+		progressHandler(stage, item, current, total)
+		return
+	}
+	printProgress(stage, item, current, total)
+}
+
+// End the terminal progress line after the last item of a stage
+func endProgress() {
+	if progressHandler == nil {
+		consolef("\n")
+	}
+}
+
+func printProgress(stage string, item string, current, total int) {
+	percentage := int((float64(current) / float64(total)) * 100)
+	consolef("\r%-80s", " ") // Clear line
+	consolef("\r%s: %s (%d of %d) - %d%%", stage, capitalizePhrase(item), current, total, percentage)
+}
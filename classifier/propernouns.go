@@ -0,0 +1,74 @@
+package classifier
+
+import (
+	"bufio"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jdkato/prose/v2"
+)
+
+// Multi-word proper-noun phrases collected across all files, with their counts
+var properNounPhrases = make(map[string]int)
+
+func isCapitalizedToken(text string) bool {
+	first, _ := utf8.DecodeRuneInString(text)
+	return unicode.IsUpper(first) && isWordText(strings.ToLower(text))
+}
+
+// Group runs of two or more capitalized tokens ("United Nations", "New York Times")
+// into phrases. A sentence-initial token only starts a phrase if the tagger
+// considers it a proper noun, so "The United Nations" yields "United Nations".
+func findProperNounPhrases(tokens []prose.Token) map[string]int {
+	phrases := make(map[string]int)
+	var run []string
+	flush := func() {
+		if len(run) >= 2 {
+			phrases[strings.Join(run, " ")]++
+		}
+		run = nil
+	}
+
+	sentenceStart := true
+	for _, tok := range tokens {
+		if !isCapitalizedToken(tok.Text) {
+			flush()
+			sentenceStart = tok.Text == "." || tok.Text == "!" || tok.Text == "?"
+			continue
+		}
+		if sentenceStart && tok.Tag != "NNP" && tok.Tag != "NNPS" {
+			sentenceStart = false
+			continue
+		}
+		sentenceStart = false
+		run = append(run, tok.Text)
+	}
+	flush()
+	return phrases
+}
+
+// Add a file's phrases to the totals when phrase output is enabled
+func addProperNounPhrases(phrases map[string]int) {
+	if !config.GenerateProperNounPhrases {
+		return
+	}
+	for phrase, count := range phrases {
+		properNounPhrases[phrase] += count
+	}
+}
+
+// Write the proper-noun phrases, most frequent first
+func writeProperNounPhrases(path string, phrases map[string]int) error {
+	file, err := createOutputFile(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for _, phrase := range sortByFrequency(phrases) {
+		writer.WriteString(phrase + "\n")
+	}
+	return writer.Flush()
+}
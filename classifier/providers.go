@@ -0,0 +1,542 @@
+package classifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DictionaryProvider looks up a word in one dictionary source.
+// A lookup that succeeds but finds nothing returns an entry without definitions;
+// errors are reserved for failures worth retrying later (timeouts, 429, 5xx).
+type DictionaryProvider interface {
+	Lookup(ctx context.Context, word string) (WordCache, error)
+}
+
+// Registered dictionary providers, keyed by the identifiers used in the sources config
+var dictionaryProviders = map[string]DictionaryProvider{
+	"dictionaryapi":  FreeDictionaryProvider{},
+	"wiktionary":     WiktionaryProvider{},
+	"merriamwebster": MerriamWebsterProvider{},
+	"offline":        OfflineProvider{},
+}
+
+// Default source order when none is configured
+var defaultSources = []string{"dictionaryapi"}
+
+// Check the configured sources against the registered providers
+func validateSources(sources []string) error {
+	if len(sources) == 0 {
+		return fmt.Errorf("no dictionary sources configured")
+	}
+	for _, name := range sources {
+		if _, ok := dictionaryProviders[name]; !ok {
+			var known []string
+			for registered := range dictionaryProviders {
+				known = append(known, registered)
+			}
+			sort.Strings(known)
+			return fmt.Errorf("unknown dictionary source '%s' (available: %s)", name, strings.Join(known, ", "))
+		}
+		if name == "merriamwebster" && queryConfig.MerriamWebsterAPIKey == "" {
+			return fmt.Errorf("the merriamwebster source needs merriamWebsterAPIKey to be set")
+		}
+	}
+	return nil
+}
+
+// Returned by fetchJSON for a 404, the dictionary's definitive "No Definitions Found"
+var errWordNotFound = errors.New("word not found")
+
+// LookupError reports a lookup that failed for a reason other than the word being
+// missing from the dictionary, so the word must not be marked unknown.
+type LookupError struct {
+	Word string
+	Err  error
+}
+
+func (e *LookupError) Error() string {
+	return fmt.Sprintf("lookup of '%s' failed: %v", e.Word, e.Err)
+}
+
+func (e *LookupError) Unwrap() error {
+	return e.Err
+}
+
+// Look the word up in the configured sources and tidy up the resulting definitions.
+// Returns a *LookupError if no definitions were found and a source failed.
+func lookupWord(ctx context.Context, word string) (WordCache, error) {
+	entry, err := lookupSources(ctx, word)
+	if err != nil {
+		return entry, err
+	}
+	entry.Definitions = deduplicateDefinitions(entry.Definitions, config.DuplicateDefinitions)
+	if config.MergeSimilarDefinitions && len(entry.Definitions) > 1 {
+		threshold := config.DefinitionSimilarityThreshold
+		if threshold <= 0 || threshold > 1 {
+			threshold = 0.8
+		}
+		entry.Definitions = mergeSimilarDefinitions(entry.Definitions, threshold)
+	}
+	return entry, nil
+}
+
+// Query each configured source in priority order. The first source with
+// definitions wins unless MergeSources combines the results of all of them.
+// OfflineMode replaces the sources with the local dictionary.
+func lookupSources(ctx context.Context, word string) (WordCache, error) {
+	var merged WordCache
+	var failure error
+	sources := queryConfig.Sources
+	if queryConfig.OfflineMode {
+		sources = []string{"offline"}
+	}
+	for _, name := range sources {
+		provider, ok := dictionaryProviders[name]
+		if !ok {
+			continue
+		}
+		if ctx.Err() != nil {
+			failure = ctx.Err()
+			break
+		}
+		entry, err := provider.Lookup(ctx, word)
+		if err != nil {
+			log.Printf("Lookup of '%s' in %s failed: %v\n", word, name, err)
+			failure = err
+			continue
+		}
+		if len(entry.Definitions) == 0 {
+			continue
+		}
+
+		for i := range entry.Definitions {
+			entry.Definitions[i].Source = name
+			if config.StripExampleAttributions {
+				entry.Definitions[i].Example = stripExampleAttribution(entry.Definitions[i].Example)
+			}
+		}
+		if !queryConfig.MergeSources {
+			return entry, nil
+		}
+		merged = mergeEntries(merged, entry)
+	}
+
+	// A source that failed might have had definitions, so don't report the word as missing
+	if len(merged.Definitions) == 0 && failure != nil {
+		return merged, &LookupError{Word: word, Err: failure}
+	}
+	return merged, nil
+}
+
+// Normalize definition text so trivially different wordings compare equal
+func normalizeDefinition(text string) string {
+	text = strings.ToLower(text)
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) {
+			return -1
+		}
+		return r
+	}, text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Union the definitions, synonyms and antonyms of two entries, dropping duplicate definitions.
+// Phonetics, origin and audio come from the first entry that has them.
+func mergeEntries(base WordCache, extra WordCache) WordCache {
+	if len(base.Definitions) == 0 && base.Phonetic == "" {
+		return extra
+	}
+
+	seen := make(map[string]bool)
+	for _, def := range base.Definitions {
+		seen[normalizeDefinition(def.Definition)] = true
+	}
+	for _, def := range extra.Definitions {
+		key := normalizeDefinition(def.Definition)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		base.Definitions = append(base.Definitions, def)
+	}
+
+	base.Synonyms = deduplicateStrings(append(base.Synonyms, extra.Synonyms...))
+	base.Antonyms = deduplicateStrings(append(base.Antonyms, extra.Antonyms...))
+	if base.Phonetic == "" {
+		base.Phonetic = extra.Phonetic
+	}
+	if len(base.Phonetics) == 0 {
+		base.Phonetics = extra.Phonetics
+	}
+	if base.Origin == "" {
+		base.Origin = extra.Origin
+	}
+	if base.AudioURL == "" {
+		base.AudioURL = extra.AudioURL
+	}
+	return base
+}
+
+// Fetch a URL with the configured proxy and return the body of a 200 response.
+// A 404 is reported as errWordNotFound. 429 and 5xx responses are retried up to
+// MaxRetries times with backoff; every attempt waits for the rate limiter.
+// Cancelling ctx aborts the wait and the request in flight.
+func fetchJSON(ctx context.Context, apiURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	req.Header.Add("Accept", "application/json")
+
+	for attempt := 0; ; attempt++ {
+		if err := waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		countAPICall()
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			// Keep API keys in the query string out of logged errors
+			if urlErr, ok := err.(*url.Error); ok {
+				urlErr.URL = withoutQuery(req.URL)
+			}
+			return nil, err
+		}
+
+		if isRetryableStatus(resp.StatusCode) && attempt < performanceConfig.MaxRetries {
+			delay := retryDelay(attempt, resp.Header.Get("Retry-After"))
+			resp.Body.Close()
+			log.Printf("%s returned %s, retrying in %v\n", withoutQuery(req.URL), resp.Status, delay)
+			// Hold back the other workers too so the retry doesn't trip the limit again
+			postponeRequests(delay)
+			continue
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, errWordNotFound
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return ioutil.ReadAll(resp.Body)
+	}
+}
+
+// URL without its query string, which may hold an API key
+func withoutQuery(u *url.URL) string {
+	stripped := *u
+	stripped.RawQuery = ""
+	return stripped.String()
+}
+
+// FreeDictionaryProvider looks words up in the free dictionaryapi.dev API
+type FreeDictionaryProvider struct{}
+
+func (FreeDictionaryProvider) Lookup(ctx context.Context, word string) (WordCache, error) {
+	apiURL := fmt.Sprintf("https://api.dictionaryapi.dev/api/v2/entries/%s/%s", url.PathEscape(dictionaryLanguage()), url.PathEscape(word))
+	bodyBytes, err := fetchJSON(ctx, apiURL)
+	if errors.Is(err, errWordNotFound) {
+		return WordCache{}, nil
+	}
+	if err != nil {
+		return WordCache{}, err
+	}
+
+	// An undecodable body, such as a captive portal page, is a failed lookup rather than
+	// a missing word; only an empty list means the dictionary has no entries
+	var result []map[string]interface{}
+	if err := json.Unmarshal(bodyBytes, &result); err != nil {
+		return WordCache{}, fmt.Errorf("invalid dictionary response: %v", err)
+	}
+	if len(result) == 0 {
+		return WordCache{}, nil
+	}
+
+	// Process API response into our cache structure
+	cachedData := WordCache{
+		Definitions: []Definition{},
+		Phonetic:    "",
+		Origin:      "",
+		Synonyms:    []string{},
+		Antonyms:    []string{},
+	}
+
+	// Extract phonetic if available
+	if phonetic, ok := result[0]["phonetic"].(string); ok {
+		cachedData.Phonetic = phonetic
+	}
+
+	// Extract every transcription with the region of its recording
+	if phonetics, ok := result[0]["phonetics"].([]interface{}); ok {
+		for _, p := range phonetics {
+			if phoneticMap, ok := p.(map[string]interface{}); ok {
+				text, _ := phoneticMap["text"].(string)
+				if text == "" {
+					continue
+				}
+				audio, _ := phoneticMap["audio"].(string)
+				cachedData.Phonetics = append(cachedData.Phonetics, Transcription{Text: text, Region: audioRegion(audio), AudioURL: audio})
+			}
+		}
+	}
+
+	// The top-level phonetic stays primary, otherwise the first transcription
+	if cachedData.Phonetic == "" && len(cachedData.Phonetics) > 0 {
+		cachedData.Phonetic = cachedData.Phonetics[0].Text
+	}
+
+	// Extract the pronunciation audio URL, preferring a US or UK recording
+	if phonetics, ok := result[0]["phonetics"].([]interface{}); ok {
+		var audioURLs []string
+		for _, p := range phonetics {
+			if phoneticMap, ok := p.(map[string]interface{}); ok {
+				if audio, ok := phoneticMap["audio"].(string); ok && audio != "" {
+					audioURLs = append(audioURLs, audio)
+				}
+			}
+		}
+		cachedData.AudioURL = preferredAudioURL(audioURLs)
+	}
+
+	// Extract origin directly from the top level
+	if originStr, ok := result[0]["origin"].(string); ok {
+		cachedData.Origin = originStr
+	}
+
+	// Extract meanings, definitions, synonyms, antonyms
+	if meanings, ok := result[0]["meanings"].([]interface{}); ok {
+		for _, m := range meanings {
+			if meaningMap, ok := m.(map[string]interface{}); ok {
+				partOfSpeech := ""
+				if pos, ok := meaningMap["partOfSpeech"].(string); ok {
+					partOfSpeech = pos
+				}
+
+				// Extract definitions
+				if definitions, ok := meaningMap["definitions"].([]interface{}); ok {
+					for _, d := range definitions {
+						defMap, ok := d.(map[string]interface{})
+						if !ok {
+							continue
+						}
+
+						def := Definition{
+							PartOfSpeech: partOfSpeech,
+							Definition:   "",
+							Example:      "",
+							Synonyms:     []string{},
+							Antonyms:     []string{},
+						}
+
+						if defStr, ok := defMap["definition"].(string); ok {
+							def.Definition = defStr
+						}
+
+						if exampleStr, ok := defMap["example"].(string); ok {
+							def.Example = exampleStr
+						}
+
+						// Extract synonyms and antonyms
+						if syns, ok := defMap["synonyms"].([]interface{}); ok {
+							for _, syn := range syns {
+								if synStr, ok := syn.(string); ok {
+									def.Synonyms = append(def.Synonyms, synStr)
+									cachedData.Synonyms = append(cachedData.Synonyms, synStr)
+								}
+							}
+						}
+
+						if ants, ok := defMap["antonyms"].([]interface{}); ok {
+							for _, ant := range ants {
+								if antStr, ok := ant.(string); ok {
+									def.Antonyms = append(def.Antonyms, antStr)
+									cachedData.Antonyms = append(cachedData.Antonyms, antStr)
+								}
+							}
+						}
+
+						cachedData.Definitions = append(cachedData.Definitions, def)
+					}
+				}
+			}
+		}
+	}
+
+	return cachedData, nil
+}
+
+// WiktionaryProvider looks words up in Wiktionary's REST definition API
+type WiktionaryProvider struct{}
+
+// Response shape of https://en.wiktionary.org/api/rest_v1/page/definition/{word}
+type wiktionaryUsage struct {
+	PartOfSpeech string `json:"partOfSpeech"`
+	Language     string `json:"language"`
+	Definitions  []struct {
+		Definition string   `json:"definition"`
+		Examples   []string `json:"examples"`
+	} `json:"definitions"`
+}
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// Remove markup from Wiktionary's HTML snippets
+func stripHTML(text string) string {
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	text = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", "\"", "&#39;", "'", "&nbsp;", " ").Replace(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func (WiktionaryProvider) Lookup(ctx context.Context, word string) (WordCache, error) {
+	apiURL := fmt.Sprintf("https://en.wiktionary.org/api/rest_v1/page/definition/%s", url.PathEscape(word))
+	bodyBytes, err := fetchJSON(ctx, apiURL)
+	if errors.Is(err, errWordNotFound) {
+		return WordCache{}, nil
+	}
+	if err != nil {
+		return WordCache{}, err
+	}
+
+	var result map[string][]wiktionaryUsage
+	if err := json.Unmarshal(bodyBytes, &result); err != nil {
+		return WordCache{}, fmt.Errorf("invalid Wiktionary response: %v", err)
+	}
+
+	cachedData := WordCache{
+		Definitions: []Definition{},
+		Synonyms:    []string{},
+		Antonyms:    []string{},
+	}
+	for _, usage := range result["en"] {
+		for _, d := range usage.Definitions {
+			def := Definition{
+				PartOfSpeech: strings.ToLower(usage.PartOfSpeech),
+				Definition:   stripHTML(d.Definition),
+				Synonyms:     []string{},
+				Antonyms:     []string{},
+			}
+			if def.Definition == "" {
+				continue
+			}
+			if len(d.Examples) > 0 {
+				def.Example = stripHTML(d.Examples[0])
+			}
+			cachedData.Definitions = append(cachedData.Definitions, def)
+		}
+	}
+	return cachedData, nil
+}
+
+// MerriamWebsterProvider looks words up in the Merriam-Webster Collegiate Dictionary
+// API, which needs the free key from dictionaryapi.com set as merriamWebsterAPIKey.
+// Only the short definitions are used, so its entries carry no examples.
+type MerriamWebsterProvider struct{}
+
+// Response shape of https://www.dictionaryapi.com/api/v3/references/collegiate/json/{word}
+type merriamWebsterEntry struct {
+	Meta struct {
+		ID string `json:"id"`
+	} `json:"meta"`
+	HeadwordInfo struct {
+		Pronunciations []struct {
+			MW    string `json:"mw"`
+			Sound struct {
+				Audio string `json:"audio"`
+			} `json:"sound"`
+		} `json:"prs"`
+	} `json:"hwi"`
+	FunctionalLabel  string     `json:"fl"`
+	ShortDefinitions []string   `json:"shortdef"`
+	Etymology        [][]string `json:"et"`
+}
+
+var merriamWebsterMarkup = regexp.MustCompile(`\{[^}]*\}`)
+
+// URL of a Merriam-Webster pronunciation recording, following their subdirectory rules
+func merriamWebsterAudioURL(audio string) string {
+	subdir := audio[:1]
+	switch {
+	case strings.HasPrefix(audio, "bix"):
+		subdir = "bix"
+	case strings.HasPrefix(audio, "gg"):
+		subdir = "gg"
+	case !unicode.IsLetter(rune(audio[0])):
+		subdir = "number"
+	}
+	return fmt.Sprintf("https://media.merriam-webster.com/audio/prons/en/us/mp3/%s/%s.mp3", subdir, audio)
+}
+
+func (MerriamWebsterProvider) Lookup(ctx context.Context, word string) (WordCache, error) {
+	apiURL := fmt.Sprintf("https://www.dictionaryapi.com/api/v3/references/collegiate/json/%s?key=%s",
+		url.PathEscape(word), url.QueryEscape(queryConfig.MerriamWebsterAPIKey))
+	bodyBytes, err := fetchJSON(ctx, apiURL)
+	if errors.Is(err, errWordNotFound) {
+		return WordCache{}, nil
+	}
+	if err != nil {
+		return WordCache{}, err
+	}
+
+	// Unknown words come back as a list of spelling suggestions instead of entries
+	var result []merriamWebsterEntry
+	if err := json.Unmarshal(bodyBytes, &result); err != nil {
+		var suggestions []string
+		if json.Unmarshal(bodyBytes, &suggestions) == nil {
+			return WordCache{}, nil
+		}
+		return WordCache{}, fmt.Errorf("invalid Merriam-Webster response: %v", err)
+	}
+
+	cachedData := WordCache{
+		Definitions: []Definition{},
+		Synonyms:    []string{},
+		Antonyms:    []string{},
+	}
+	for _, entry := range result {
+		// Skip related entries such as "fox terrier"; homograph ids look like "fox:2"
+		headword := strings.SplitN(entry.Meta.ID, ":", 2)[0]
+		if !strings.EqualFold(headword, word) {
+			continue
+		}
+
+		if cachedData.Phonetic == "" && len(entry.HeadwordInfo.Pronunciations) > 0 {
+			pronunciation := entry.HeadwordInfo.Pronunciations[0]
+			if pronunciation.MW != "" {
+				cachedData.Phonetic = "\\" + pronunciation.MW + "\\"
+			}
+			if pronunciation.Sound.Audio != "" {
+				cachedData.AudioURL = merriamWebsterAudioURL(pronunciation.Sound.Audio)
+			}
+		}
+		if cachedData.Origin == "" {
+			for _, part := range entry.Etymology {
+				if len(part) == 2 && part[0] == "text" {
+					cachedData.Origin = strings.Join(strings.Fields(merriamWebsterMarkup.ReplaceAllString(part[1], "")), " ")
+					break
+				}
+			}
+		}
+
+		for _, shortDef := range entry.ShortDefinitions {
+			cachedData.Definitions = append(cachedData.Definitions, Definition{
+				PartOfSpeech: entry.FunctionalLabel,
+				Definition:   shortDef,
+				Synonyms:     []string{},
+				Antonyms:     []string{},
+			})
+		}
+	}
+	return cachedData, nil
+}
@@ -0,0 +1,134 @@
+package classifier
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// QuizQuestion is a multiple-choice question asking for the definition of a word
+type QuizQuestion struct {
+	Word    string
+	Options []string
+	Answer  int
+}
+
+// First definition of a cached word, used as the correct answer or a distractor
+func quizDefinition(word string) string {
+	cachedData, exists := wordCache[lowerWord(word)]
+	if !exists {
+		return ""
+	}
+	for _, def := range cachedData.Definitions {
+		if def.Definition != "" {
+			return def.Definition
+		}
+	}
+	return ""
+}
+
+// Pick up to count definitions of other words, preferring the word's own category
+func quizDistractors(r *rand.Rand, word string, answer string, sameCategory []string, allWords []string, count int) []string {
+	var distractors []string
+	used := map[string]bool{answer: true}
+	for _, pool := range [][]string{sameCategory, allWords} {
+		candidates := append([]string(nil), pool...)
+		r.Shuffle(len(candidates), func(i, j int) {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		})
+		for _, candidate := range candidates {
+			if len(distractors) == count {
+				return distractors
+			}
+			if candidate == word {
+				continue
+			}
+			definition := quizDefinition(candidate)
+			if definition == "" || used[definition] {
+				continue
+			}
+			used[definition] = true
+			distractors = append(distractors, definition)
+		}
+	}
+	return distractors
+}
+
+// Build one question per known word, drawing distractors from words of the same category
+func buildQuiz(wordsByCategory map[string][]string, distractorCount int) []QuizQuestion {
+	r := randFor("quiz")
+
+	var categories []string
+	for category := range wordsByCategory {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	var allWords []string
+	for _, category := range categories {
+		allWords = append(allWords, wordsByCategory[category]...)
+	}
+
+	var questions []QuizQuestion
+	asked := make(map[string]bool)
+	for _, category := range categories {
+		for _, word := range wordsByCategory[category] {
+			answer := quizDefinition(word)
+			if answer == "" || asked[word] {
+				continue
+			}
+			asked[word] = true
+
+			options := append(quizDistractors(r, word, answer, wordsByCategory[category], allWords, distractorCount), answer)
+			r.Shuffle(len(options), func(i, j int) {
+				options[i], options[j] = options[j], options[i]
+			})
+			correct := 0
+			for i, option := range options {
+				if option == answer {
+					correct = i
+				}
+			}
+			questions = append(questions, QuizQuestion{Word: word, Options: options, Answer: correct})
+		}
+	}
+	return questions
+}
+
+// Write the quiz and its answer key. Returns the number of questions written.
+func writeQuiz(quizPath string, answersPath string, wordsByCategory map[string][]string) (int, error) {
+	distractorCount := config.QuizDistractors
+	if distractorCount <= 0 {
+		distractorCount = 3
+	}
+	questions := buildQuiz(wordsByCategory, distractorCount)
+
+	quizFile, err := createOutputFile(quizPath)
+	if err != nil {
+		return 0, err
+	}
+	defer quizFile.Close()
+
+	answersFile, err := createOutputFile(answersPath)
+	if err != nil {
+		return 0, err
+	}
+	defer answersFile.Close()
+
+	quizWriter := bufio.NewWriter(quizFile)
+	answersWriter := bufio.NewWriter(answersFile)
+	for i, question := range questions {
+		quizWriter.WriteString(fmt.Sprintf("%d. %s\n", i+1, capitalizePhrase(question.Word)))
+		for j, option := range question.Options {
+			quizWriter.WriteString(fmt.Sprintf("%s%c) %s\n", indent(1), 'A'+j, option))
+		}
+		quizWriter.WriteString("\n")
+
+		answersWriter.WriteString(fmt.Sprintf("%d. %c (%s)\n", i+1, 'A'+question.Answer, capitalizePhrase(question.Word)))
+	}
+
+	if err := quizWriter.Flush(); err != nil {
+		return 0, err
+	}
+	return len(questions), answersWriter.Flush()
+}
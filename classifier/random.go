@@ -0,0 +1,22 @@
+package classifier
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"time"
+)
+
+// Random source for output choices when no randomSeed is configured, seeded once at startup
+var outputRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// Random source for one output choice, e.g. the examples picked for a word. With
+// a randomSeed each key gets its own source derived from the seed, so the result
+// doesn't depend on the order words are processed in and runs can be diffed.
+func randFor(key string) *rand.Rand {
+	if config.RandomSeed == 0 {
+		return outputRand
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	return rand.New(rand.NewSource(config.RandomSeed ^ int64(hash.Sum64())))
+}
@@ -0,0 +1,53 @@
+package classifier
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var (
+	rateLimitMutex sync.Mutex
+	nextRequestAt  time.Time
+)
+
+// Block until the next dictionary request may be sent under RateLimitPerSecond, or
+// until a postponement after a 429 or 5xx has passed. Requests are spaced evenly, so
+// concurrent workers queue up instead of failing with 429 responses. Cache hits never
+// get here. Returns ctx's error if it is cancelled while waiting.
+func waitForRateLimit(ctx context.Context) error {
+	var interval time.Duration
+	if limit := performanceConfig.RateLimitPerSecond; limit > 0 {
+		interval = time.Duration(float64(time.Second) / limit)
+	}
+
+	rateLimitMutex.Lock()
+	now := time.Now()
+	if nextRequestAt.Before(now) {
+		nextRequestAt = now
+	}
+	wait := nextRequestAt.Sub(now)
+	nextRequestAt = nextRequestAt.Add(interval)
+	rateLimitMutex.Unlock()
+
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Make the next request wait at least delay, whether or not a rate limit is set
+func postponeRequests(delay time.Duration) {
+	rateLimitMutex.Lock()
+	if until := time.Now().Add(delay); until.After(nextRequestAt) {
+		nextRequestAt = until
+	}
+	rateLimitMutex.Unlock()
+}
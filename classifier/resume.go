@@ -0,0 +1,171 @@
+package classifier
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Set by WithResume, from the -resume and -force flags
+var (
+	resumeRun bool
+	forceRun  bool
+)
+
+// Progress file in the output directory, removed once a run completes
+const resumeStateName = ".resume_state"
+
+// Statuses of a processed word in the resume state
+const (
+	resumeKnown   = "known"   // Written to the category files
+	resumeExample = "example" // Written, including example sentences
+	resumeUnknown = "unknown" // Listed with the unknown words
+)
+
+// resumeState records which words of each category have been written, so a run
+// interrupted during the category files can continue where it stopped. Each
+// word is recorded right after its lines are flushed to the category files.
+type resumeState struct {
+	path   string
+	file   *os.File
+	status map[string]map[string]string // Category -> word -> status
+	order  map[string][]string          // Category -> words in the order they were written
+}
+
+// Start tracking progress in outputDir. With -resume (and without -force) the
+// progress of an interrupted run is loaded and its category files are appended
+// to. Returns nil when progress can't be tracked because SkipUnchangedOutputs
+// keeps output in memory until each file is complete.
+func startResumeState(outputDir string) (*resumeState, error) {
+	if config.SkipUnchangedOutputs {
+		if resumeRun {
+			Statusln("Resume is not available with skipUnchangedOutputs, regenerating all output")
+		}
+		return nil, nil
+	}
+
+	state := &resumeState{
+		path:   filepath.Join(outputDir, resumeStateName),
+		status: make(map[string]map[string]string),
+		order:  make(map[string][]string),
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resumeRun && !forceRun {
+		if err := state.load(); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		written := 0
+		for _, words := range state.order {
+			written += len(words)
+		}
+		if written > 0 {
+			Statusf("Resuming interrupted run: %d words already written\n", written)
+		} else {
+			Statusln("No interrupted run to resume, processing all words")
+		}
+	}
+
+	file, err := os.OpenFile(state.path, flags, 0644)
+	if err != nil {
+		return nil, err
+	}
+	state.file = file
+	return state, nil
+}
+
+// Read the "category<TAB>word<TAB>status" lines of an earlier run
+func (s *resumeState) load() error {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		s.add(fields[0], fields[1], fields[2])
+	}
+	return scanner.Err()
+}
+
+func (s *resumeState) add(category string, word string, status string) {
+	if s.status[category] == nil {
+		s.status[category] = make(map[string]string)
+	}
+	if _, exists := s.status[category][word]; !exists {
+		s.order[category] = append(s.order[category], word)
+	}
+	s.status[category][word] = status
+}
+
+// Whether a category has words from an earlier run, so its files are appended to
+func (s *resumeState) resuming(category string) bool {
+	return s != nil && len(s.order[category]) > 0
+}
+
+// Status of a word written by an earlier run; false if it still has to be processed
+func (s *resumeState) done(category string, word string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	status, ok := s.status[category][word]
+	return status, ok
+}
+
+// Words of a category written by an earlier run, in order
+func (s *resumeState) written(category string) []string {
+	if s == nil {
+		return nil
+	}
+	return s.order[category]
+}
+
+// Record a processed word. Its output must already be flushed to disk.
+func (s *resumeState) record(category string, word string, status string) {
+	if s == nil {
+		return
+	}
+	s.add(category, word, status)
+	if _, err := fmt.Fprintf(s.file, "%s\t%s\t%s\n", category, word, status); err != nil {
+		log.Printf("Error recording progress in %s: %v\n", s.path, err)
+	}
+}
+
+// Close the progress file, keeping it for -resume. Safe to call more than once.
+func (s *resumeState) close() {
+	if s == nil || s.file == nil {
+		return
+	}
+	s.file.Close()
+	s.file = nil
+}
+
+// Remove the progress file once every output file is complete
+func (s *resumeState) finish() {
+	if s == nil {
+		return
+	}
+	s.close()
+	os.Remove(s.path)
+}
+
+// Open a category file, appending to it if an earlier run already wrote to it
+func openCategoryFile(path string, state *resumeState, category string) (*outputFile, error) {
+	if state.resuming(category) {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		return &outputFile{path: path, file: file}, nil
+	}
+	return createOutputFile(path)
+}
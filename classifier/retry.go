@@ -0,0 +1,38 @@
+package classifier
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Whether a response status is worth retrying: rate limiting or a server error
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// Delay before retry number attempt (starting at 0). A Retry-After header, given in
+// seconds or as an HTTP date, takes precedence over exponential backoff with jitter.
+func retryDelay(attempt int, retryAfter string) time.Duration {
+	if retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if when, err := http.ParseTime(retryAfter); err == nil {
+			if delay := time.Until(when); delay > 0 {
+				return delay
+			}
+			return 0
+		}
+	}
+
+	base := time.Duration(performanceConfig.RetryBaseDelayMs) * time.Millisecond
+	if base <= 0 {
+		base = 500 * time.Millisecond
+	}
+	backoff := base << uint(attempt)
+	// Up to 50% jitter keeps parallel workers from retrying in lockstep
+	jitter := time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+	return backoff + jitter
+}
@@ -0,0 +1,70 @@
+package classifier
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// ReverseIndexEntry pairs a definition or example with the word it belongs to
+type ReverseIndexEntry struct {
+	Clue         string
+	PartOfSpeech string
+	Word         string
+}
+
+// Collect definition (or example) clues for the given words, one entry per word and clue
+func buildReverseIndex(words []string) []ReverseIndexEntry {
+	var entries []ReverseIndexEntry
+	seen := make(map[string]bool)
+	for _, word := range words {
+		cachedData, exists := wordCache[lowerWord(word)]
+		if !exists {
+			continue
+		}
+		for _, def := range cachedData.Definitions {
+			clue := def.Definition
+			if config.ReverseIndexSource == "examples" {
+				clue = exampleOf(def)
+			}
+			if clue == "" {
+				continue
+			}
+
+			// Shared generic definitions stay as separate entries for each word
+			key := strings.ToLower(word) + "\x00" + clue
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			entries = append(entries, ReverseIndexEntry{Clue: clue, PartOfSpeech: def.PartOfSpeech, Word: word})
+		}
+	}
+
+	if config.ShuffleReverseIndex {
+		r := randFor("reverseindex")
+		r.Shuffle(len(entries), func(i, j int) {
+			entries[i], entries[j] = entries[j], entries[i]
+		})
+	}
+	return entries
+}
+
+// Write the reverse index, listing each clue followed by the word it describes.
+// Returns the number of entries written.
+func writeReverseIndex(path string, words []string) (int, error) {
+	entries := buildReverseIndex(words)
+
+	file, err := createOutputFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for i, entry := range entries {
+		writer.WriteString(fmt.Sprintf("%d. (%s) %s\n", i+1, entry.PartOfSpeech, entry.Clue))
+		writer.WriteString(fmt.Sprintf("%sAnswer: %s\n", indent(1), capitalizePhrase(entry.Word)))
+	}
+	return len(entries), writer.Flush()
+}
@@ -0,0 +1,49 @@
+package classifier
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"time"
+)
+
+// Tool version recorded in run_metadata.json, set at build time with
+// -ldflags "-X github.com/ljg-cqu/txt-ewClassifiers/classifier.version=..."
+var version = "dev"
+
+// RunMetadata describes how an output directory was produced
+type RunMetadata struct {
+	StartedAt      time.Time    `json:"startedAt"`
+	FinishedAt     time.Time    `json:"finishedAt"`
+	Version        string       `json:"version"`
+	InputDirectory string       `json:"inputDirectory"`
+	ProcessedFiles []string     `json:"processedFiles"`
+	OutputConfig   OutputConfig `json:"outputConfig"`
+	QueryConfig    QueryConfig  `json:"queryConfig"`
+}
+
+// Write run_metadata.json with the effective configuration and the files processed.
+// The proxy settings are left out since proxy URLs may carry credentials, and API
+// keys are redacted.
+func writeRunMetadata(outputDir string, inputDir string, startedAt time.Time, processedFiles []string) error {
+	metadata := RunMetadata{
+		StartedAt:      startedAt,
+		FinishedAt:     time.Now(),
+		Version:        version,
+		InputDirectory: inputDir,
+		ProcessedFiles: []string{},
+		OutputConfig:   config,
+		QueryConfig:    queryConfig,
+	}
+	if metadata.QueryConfig.MerriamWebsterAPIKey != "" {
+		metadata.QueryConfig.MerriamWebsterAPIKey = "(redacted)"
+	}
+	for _, file := range processedFiles {
+		metadata.ProcessedFiles = append(metadata.ProcessedFiles, filepath.ToSlash(file))
+	}
+
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeOutputFile(filepath.Join(outputDir, "run_metadata.json"), data)
+}
@@ -0,0 +1,119 @@
+package classifier
+
+import (
+	"sync"
+	"time"
+)
+
+// RunStats summarizes where a processing run spent its time and how well the
+// word cache served it
+type RunStats struct {
+	Words       int           // Word occurrences counted in the input files
+	UniqueWords int           // Distinct words counted
+	CacheHits   int           // Words first answered from word_cache.json or word_unknown.json
+	CacheMisses int           // Words that needed a dictionary lookup when first asked for
+	APICalls    int           // Dictionary requests sent, retries included
+	NewUnknowns int           // Words newly marked unknown
+	ParseTime   time.Duration // Time spent reading and tokenizing the input files
+	LookupTime  time.Duration // Wall-clock time during which at least one lookup was running
+	TotalTime   time.Duration
+}
+
+// Counters of the current run, reset by processAllFiles. Lookups run
+// concurrently, so every update holds statsMutex.
+var (
+	runStats        RunStats
+	statsMutex      sync.Mutex
+	lookupsInFlight int
+	lookupsStarted  time.Time
+	statsWords      = make(map[string]bool) // Words already counted as a hit or miss
+)
+
+func resetRunStats() {
+	statsMutex.Lock()
+	defer statsMutex.Unlock()
+	runStats = RunStats{}
+	lookupsInFlight = 0
+	statsWords = make(map[string]bool)
+}
+
+// Count whether a word was answered from the caches (hit) or sent to the
+// dictionaries. Every phase asks for the same words, so only the first counts.
+func countCacheResult(word string, hit bool) {
+	statsMutex.Lock()
+	defer statsMutex.Unlock()
+	if statsWords[word] {
+		return
+	}
+	statsWords[word] = true
+	if hit {
+		runStats.CacheHits++
+	} else {
+		runStats.CacheMisses++
+	}
+}
+
+func countAPICall() {
+	statsMutex.Lock()
+	runStats.APICalls++
+	statsMutex.Unlock()
+}
+
+func countNewUnknown() {
+	statsMutex.Lock()
+	runStats.NewUnknowns++
+	statsMutex.Unlock()
+}
+
+func addParseTime(elapsed time.Duration) {
+	statsMutex.Lock()
+	runStats.ParseTime += elapsed
+	statsMutex.Unlock()
+}
+
+// Mark the start and end of a lookup. Overlapping lookups are timed once, so
+// LookupTime stays comparable with ParseTime however many workers run.
+func startLookupTimer() {
+	statsMutex.Lock()
+	defer statsMutex.Unlock()
+	if lookupsInFlight == 0 {
+		lookupsStarted = time.Now()
+	}
+	lookupsInFlight++
+}
+
+func stopLookupTimer() {
+	statsMutex.Lock()
+	defer statsMutex.Unlock()
+	lookupsInFlight--
+	if lookupsInFlight == 0 {
+		runStats.LookupTime += time.Since(lookupsStarted)
+	}
+}
+
+// Complete the counters with the word counts and the run's duration, and log
+// and print the summary
+func finishRunStats(allWords map[string]int, startedAt time.Time) RunStats {
+	statsMutex.Lock()
+	runStats.UniqueWords = len(allWords)
+	runStats.Words = 0
+	for _, count := range allWords {
+		runStats.Words += count
+	}
+	runStats.TotalTime = time.Since(startedAt)
+	stats := runStats
+	statsMutex.Unlock()
+
+	hitRate := 0.0
+	if lookedUp := stats.CacheHits + stats.CacheMisses; lookedUp > 0 {
+		hitRate = float64(stats.CacheHits) / float64(lookedUp) * 100
+	}
+	Statusf("\n===== Run Statistics =====\n")
+	Statusf("Words processed: %d (%d unique)\n", stats.Words, stats.UniqueWords)
+	Statusf("Cache hit rate: %.1f%% (%d hits, %d misses)\n", hitRate, stats.CacheHits, stats.CacheMisses)
+	Statusf("Dictionary requests: %d\n", stats.APICalls)
+	Statusf("New unknown words: %d\n", stats.NewUnknowns)
+	Statusf("Time parsing: %s, in lookups: %s, total: %s\n",
+		stats.ParseTime.Round(time.Millisecond), stats.LookupTime.Round(time.Millisecond), stats.TotalTime.Round(time.Millisecond))
+	return stats
+}
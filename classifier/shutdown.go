@@ -0,0 +1,48 @@
+package classifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrInterrupted is returned by ProcessDirectory when it stopped early because
+// its context was cancelled or Interrupt was called. The error also wraps the
+// context's error, so errors.Is(err, context.DeadlineExceeded) tells a timeout.
+var ErrInterrupted = errors.New("interrupted")
+
+var (
+	runMutex  sync.Mutex
+	cancelRun context.CancelFunc // Cancels the running ProcessDirectory, if any
+)
+
+// Derive the context of a run that Interrupt can cancel
+func startRun(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	runMutex.Lock()
+	cancelRun = cancel
+	runMutex.Unlock()
+	return ctx, func() {
+		runMutex.Lock()
+		cancelRun = nil
+		runMutex.Unlock()
+		cancel()
+	}
+}
+
+// Interrupt asks a running ProcessDirectory to stop after the current word, so
+// open output files are flushed and progress recorded for a resumed run. It is
+// safe to call from a signal handler goroutine.
+func Interrupt() {
+	runMutex.Lock()
+	defer runMutex.Unlock()
+	if cancelRun != nil {
+		cancelRun()
+	}
+}
+
+// The error for a run stopped because ctx is done
+func interruptedError(ctx context.Context) error {
+	return fmt.Errorf("%w: %w", ErrInterrupted, ctx.Err())
+}
@@ -0,0 +1,87 @@
+package classifier
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var spokenNumbers = []string{
+	"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten",
+	"eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen", "twenty",
+}
+
+// Spell out small numbers so a TTS engine reads them naturally
+func spokenNumber(n int) string {
+	if n >= 0 && n < len(spokenNumbers) {
+		return spokenNumbers[n]
+	}
+	return strconv.Itoa(n)
+}
+
+// End a spoken sentence with a full stop unless it already has terminal punctuation
+func spokenSentence(text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" || strings.HasSuffix(text, ".") || strings.HasSuffix(text, "!") || strings.HasSuffix(text, "?") {
+		return text
+	}
+	return text + "."
+}
+
+// Render a cached word as prose to be read aloud. Phonetic notation is omitted
+// because the TTS engine pronounces the word itself.
+func speechScriptContent(word string) string {
+	cachedData, exists := wordCache[lowerWord(word)]
+	if !exists || len(cachedData.Definitions) == 0 {
+		return ""
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("The word '%s'.\n", strings.ToLower(word)))
+
+	defNumber := 0
+	lastPartOfSpeech := ""
+	for _, def := range cachedData.Definitions {
+		example := exampleOf(def)
+		if config.FilterNoExample && example == "" {
+			continue
+		}
+		defNumber++
+
+		// Announce the part of speech when it changes
+		if def.PartOfSpeech != "" && def.PartOfSpeech != lastPartOfSpeech {
+			output.WriteString(capitalizeSentence(spokenSentence(def.PartOfSpeech)) + "\n")
+			lastPartOfSpeech = def.PartOfSpeech
+		}
+
+		output.WriteString(fmt.Sprintf("Definition %s: %s\n", spokenNumber(defNumber), spokenSentence(def.Definition)))
+		if example != "" {
+			output.WriteString(fmt.Sprintf("For example: %s\n", spokenSentence(example)))
+		}
+		if config.IncludeSynonyms && len(def.Synonyms) > 0 {
+			output.WriteString(fmt.Sprintf("Similar words: %s\n", spokenSentence(strings.Join(def.Synonyms, ", "))))
+		}
+		if config.IncludeAntonyms && len(def.Antonyms) > 0 {
+			output.WriteString(fmt.Sprintf("Opposite words: %s\n", spokenSentence(strings.Join(def.Antonyms, ", "))))
+		}
+	}
+	return output.String()
+}
+
+// Write the speech script for the given words, separating words with a blank line as a pause
+func writeSpeechScript(path string, words []string) error {
+	file, err := createOutputFile(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for _, word := range words {
+		if content := speechScriptContent(word); content != "" {
+			writer.WriteString(content + "\n")
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,72 @@
+package classifier
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+)
+
+// Built-in English function words: articles, pronouns, prepositions, conjunctions
+// and auxiliaries. Content words are deliberately left out.
+var defaultStopwords = []string{
+	"a", "an", "the",
+	"i", "me", "my", "mine", "myself", "you", "your", "yours", "yourself", "yourselves",
+	"he", "him", "his", "himself", "she", "her", "hers", "herself", "it", "its", "itself",
+	"we", "us", "our", "ours", "ourselves", "they", "them", "their", "theirs", "themselves",
+	"this", "that", "these", "those", "who", "whom", "whose", "which", "what",
+	"am", "is", "are", "was", "were", "be", "been", "being",
+	"have", "has", "had", "having", "do", "does", "did",
+	"will", "would", "shall", "should", "can", "could", "may", "might", "must",
+	"and", "but", "or", "nor", "so", "yet", "if", "because", "as", "than", "while", "though",
+	"of", "to", "in", "on", "at", "by", "for", "with", "from", "into", "onto", "about",
+	"over", "under", "up", "down", "out", "off", "through", "between", "among", "during",
+	"before", "after", "above", "below", "against", "without", "within",
+	"not", "no", "there", "here", "then", "too", "very", "just",
+}
+
+// Active stopwords, lowercased; nil when FilterStopwords is off
+var stopwords map[string]bool
+
+// Identifies the active stopword list in token cache keys
+var stopwordsKey string
+
+// Build the stopword set from the defaults and StopwordsFile. With
+// ReplaceDefaultStopwords the file replaces the defaults instead of extending them.
+func loadStopwords() error {
+	stopwords, stopwordsKey = nil, ""
+	if !config.FilterStopwords {
+		return nil
+	}
+
+	var words []string
+	if !config.ReplaceDefaultStopwords {
+		words = append(words, defaultStopwords...)
+	}
+	if config.StopwordsFile != "" {
+		fileWords, err := loadWordList(config.StopwordsFile)
+		if err != nil {
+			return err
+		}
+		words = append(words, fileWords...)
+	}
+
+	stopwords = make(map[string]bool)
+	for _, word := range words {
+		stopwords[lowerWord(word)] = true
+	}
+
+	var sorted []string
+	for word := range stopwords {
+		sorted = append(sorted, word)
+	}
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+	stopwordsKey = hex.EncodeToString(sum[:8])
+	return nil
+}
+
+// Whether a lowercased token should be skipped as a stopword
+func isStopword(word string) bool {
+	return stopwords[word]
+}
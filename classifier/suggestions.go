@@ -0,0 +1,172 @@
+package classifier
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Most suggestions listed for one unknown word
+const maxSuggestionsPerWord = 3
+
+// British and American spelling pairs tried before edit distance ("colour" /
+// "color", "organise" / "organize", "centre" / "center", "travelled" / "traveled")
+var spellingVariants = [][2]string{
+	{"our", "or"},
+	{"ise", "ize"},
+	{"isation", "ization"},
+	{"yse", "yze"},
+	{"tre", "ter"},
+	{"lled", "led"},
+	{"lling", "ling"},
+	{"ogue", "og"},
+	{"ence", "ense"},
+}
+
+// Words suggestions are drawn from: the SuggestionWordList file if configured,
+// otherwise every cached word with definitions
+func suggestionCandidates() ([]string, error) {
+	if config.SuggestionWordList != "" {
+		return loadWordList(config.SuggestionWordList)
+	}
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	var words []string
+	for word, entry := range wordCache {
+		if len(entry.Definitions) > 0 {
+			words = append(words, word)
+		}
+	}
+	sort.Strings(words)
+	return words, nil
+}
+
+// British or American spelling of a word found among the known words, or ""
+func spellingVariant(word string, known map[string]bool) string {
+	for _, pair := range spellingVariants {
+		for _, swap := range [][2]string{pair, {pair[1], pair[0]}} {
+			if index := strings.LastIndex(word, swap[0]); index > 0 {
+				variant := word[:index] + swap[1] + word[index+len(swap[0]):]
+				if known[variant] {
+					return variant
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// Levenshtein distance between two words, giving up with limit+1 once the
+// distance is known to exceed limit
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			rowMin = min(rowMin, current[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// Known words within MaxSuggestionDistance edits of a word, closest first
+func closestWords(word string, candidates []string) []string {
+	type match struct {
+		word     string
+		distance int
+	}
+	limit := config.MaxSuggestionDistance
+	if limit <= 0 {
+		limit = 2
+	}
+	length := utf8.RuneCountInString(word)
+	var matches []match
+	for _, candidate := range candidates {
+		if candidate == word {
+			continue
+		}
+		diff := utf8.RuneCountInString(candidate) - length
+		if diff > limit || -diff > limit {
+			continue
+		}
+		if distance := editDistance(word, candidate, limit); distance <= limit {
+			matches = append(matches, match{candidate, distance})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+
+	var words []string
+	for i := 0; i < len(matches) && i < maxSuggestionsPerWord; i++ {
+		words = append(words, matches[i].word)
+	}
+	return words
+}
+
+// Write "word → did you mean X" for each unknown word with a spelling variant
+// or close match among the candidate words. Returns the number of words with
+// suggestions.
+func writeUnknownSuggestions(path string, unknownWords []string) (int, error) {
+	candidates, err := suggestionCandidates()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read suggestion word list: %v", err)
+	}
+	known := make(map[string]bool, len(candidates))
+	for _, word := range candidates {
+		known[word] = true
+	}
+
+	file, err := createOutputFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	suggested := 0
+	for i, word := range unknownWords {
+		reportProgress("Suggesting spellings", word, i+1, len(unknownWords))
+		lower := lowerWord(word)
+
+		// A spelling variant is the likeliest match, so it comes first
+		variant := spellingVariant(lower, known)
+		var suggestions []string
+		if variant != "" {
+			suggestions = append(suggestions, variant)
+		}
+		for _, match := range closestWords(lower, candidates) {
+			if len(suggestions) < maxSuggestionsPerWord && match != variant {
+				suggestions = append(suggestions, match)
+			}
+		}
+		if len(suggestions) == 0 {
+			continue
+		}
+
+		for j, suggestion := range suggestions {
+			suggestions[j] = capitalizePhrase(suggestion)
+		}
+		writer.WriteString(fmt.Sprintf("%s → did you mean %s\n", capitalizePhrase(word), strings.Join(suggestions, ", ")))
+		suggested++
+	}
+	endProgress()
+	return suggested, writer.Flush()
+}
@@ -0,0 +1,125 @@
+package classifier
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Estimate the number of syllables in an English word or phrase
+func countSyllables(text string) int {
+	total := 0
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '/'
+	}) {
+		total += countWordSyllables(word)
+	}
+	return total
+}
+
+func isSyllableVowel(r rune, prev rune, first bool) bool {
+	if strings.ContainsRune("aeiou", r) {
+		return true
+	}
+	// "y" is a vowel unless it starts the word or follows another vowel ("yes", "play")
+	return r == 'y' && !first && !strings.ContainsRune("aeiou", prev)
+}
+
+// Whether the vowel at i starts a new syllable although it follows another vowel:
+// "ia" and "io" are two syllables ("lion", "media") except in endings such as
+// "-tion", "-sion" and "-cial", where the "i" is silent
+func splitsVowelPair(runes []rune, i int) bool {
+	if i < 2 || runes[i-1] != 'i' || (runes[i] != 'a' && runes[i] != 'o') {
+		return false
+	}
+	return !strings.ContainsRune("tscgxaeiou", runes[i-2])
+}
+
+func countWordSyllables(word string) int {
+	runes := []rune(word)
+	if len(runes) == 0 {
+		return 0
+	}
+	if len(runes) <= 3 {
+		return 1
+	}
+
+	// Count groups of consecutive vowels
+	count := 0
+	inVowelGroup := false
+	var prev rune
+	for i, r := range runes {
+		vowel := isSyllableVowel(r, prev, i == 0)
+		if vowel && (!inVowelGroup || splitsVowelPair(runes, i)) {
+			count++
+		}
+		inVowelGroup = vowel
+		prev = r
+	}
+
+	// A trailing silent "e" ("make") doesn't add a syllable, but "-le" after a consonant does ("table")
+	n := len(runes)
+	if runes[n-1] == 'e' && !strings.HasSuffix(word, "ee") && !strings.HasSuffix(word, "ye") {
+		if !(strings.HasSuffix(word, "le") && !strings.ContainsRune("aeiouy", runes[n-3])) {
+			count--
+		}
+	}
+	// Past tense "-ed" is usually silent except after "t" or "d" ("walked" vs "wanted")
+	if strings.HasSuffix(word, "ed") && n > 3 && !strings.ContainsRune("tdaeiou", runes[n-3]) {
+		count--
+	}
+	// Plural "-es" is silent except after sibilants ("makes" vs "boxes")
+	if strings.HasSuffix(word, "es") && n > 3 && !strings.ContainsRune("sxzhcgaeiouy", runes[n-3]) {
+		count--
+	}
+
+	if count < 1 {
+		return 1
+	}
+	return count
+}
+
+// Label for a syllable-count tier
+func syllableTierName(syllables int) string {
+	switch syllables {
+	case 1:
+		return "Monosyllabic"
+	case 2:
+		return "Bisyllabic"
+	case 3:
+		return "Trisyllabic"
+	default:
+		return fmt.Sprintf("Polysyllabic (%d syllables)", syllables)
+	}
+}
+
+// Write the words grouped into syllable-count tiers, keeping their order within each tier
+func writeSyllableTiers(path string, words []string) error {
+	tiers := make(map[int][]string)
+	for _, word := range words {
+		syllables := countSyllables(word)
+		tiers[syllables] = append(tiers[syllables], word)
+	}
+
+	var counts []int
+	for syllables := range tiers {
+		counts = append(counts, syllables)
+	}
+	sort.Ints(counts)
+
+	file, err := createOutputFile(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for _, syllables := range counts {
+		writer.WriteString(fmt.Sprintf("%s (%d words)\n", syllableTierName(syllables), len(tiers[syllables])))
+		for _, word := range tiers[syllables] {
+			writer.WriteString(indent(1) + capitalizePhrase(word) + "\n")
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,76 @@
+package classifier
+
+import "testing"
+
+func TestCountSyllables(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		// Short words and plain vowel groups
+		{"a", 1},
+		{"the", 1},
+		{"happy", 2},
+		{"beautiful", 3},
+		{"education", 4},
+
+		// Silent final "e"
+		{"make", 1},
+		{"time", 1},
+		{"house", 1},
+		{"free", 1},
+		{"alive", 2},
+
+		// "-le" after a consonant is its own syllable
+		{"table", 2},
+		{"little", 2},
+		{"circle", 2},
+		{"syllable", 3},
+		{"whale", 1},
+
+		// "-ed" is silent except after "t" or "d"
+		{"walked", 1},
+		{"jumped", 1},
+		{"smiled", 1},
+		{"played", 1},
+		{"cried", 1},
+		{"wanted", 2},
+		{"needed", 2},
+		{"agreed", 2},
+
+		// Plural "-es" is silent except after sibilants
+		{"makes", 1},
+		{"boxes", 2},
+
+		// Diphthongs and vowel digraphs are one syllable
+		{"rain", 1},
+		{"boat", 1},
+		{"coin", 1},
+		{"toy", 1},
+		{"play", 1},
+		{"queue", 1},
+		{"people", 2},
+
+		// "ia" and "io" are two, except in "-tion" style endings
+		{"lion", 2},
+		{"radio", 3},
+		{"media", 3},
+		{"piano", 3},
+		{"nation", 2},
+		{"special", 2},
+		{"region", 2},
+
+		// "y" is a consonant at the start of a word
+		{"yes", 1},
+
+		// Phrases and compounds add up their words
+		{"hello world", 3},
+		{"well-known", 2},
+		{"and/or", 2},
+	}
+	for _, test := range tests {
+		if got := countSyllables(test.text); got != test.want {
+			t.Errorf("countSyllables(%q) = %d, want %d", test.text, got, test.want)
+		}
+	}
+}
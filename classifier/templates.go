@@ -0,0 +1,134 @@
+package classifier
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// ExplanationData is the data passed to explanation templates
+type ExplanationData struct {
+	Word        string
+	Category    string
+	Phonetic    string
+	Phonetics   []Transcription
+	Origin      string
+	AudioURL    string
+	Definitions []ExplanationDefinition
+}
+
+// ExplanationDefinition is a single numbered definition in ExplanationData
+type ExplanationDefinition struct {
+	Number       int
+	PartOfSpeech string
+	Definition   string
+	Example      string
+	Synonyms     []string
+	Antonyms     []string
+}
+
+// Compiled explanation templates: the global one under "" and per-category overrides
+var explanationTemplates = make(map[string]*template.Template)
+
+var templateFuncs = template.FuncMap{
+	"join":   strings.Join,
+	"indent": indent,
+	"upper":  strings.ToUpper,
+	"lower":  strings.ToLower,
+}
+
+// Parse the configured explanation templates so mistakes are reported at startup
+func compileExplanationTemplates() error {
+	explanationTemplates = make(map[string]*template.Template)
+
+	if config.ExplanationTemplate != "" {
+		tmpl, err := template.New("explanation").Funcs(templateFuncs).Parse(config.ExplanationTemplate)
+		if err != nil {
+			return fmt.Errorf("invalid explanationTemplate: %v", err)
+		}
+		explanationTemplates[""] = tmpl
+	}
+
+	for category, text := range config.CategoryTemplates {
+		tmpl, err := template.New(category).Funcs(templateFuncs).Parse(text)
+		if err != nil {
+			return fmt.Errorf("invalid template for category %s: %v", category, err)
+		}
+		explanationTemplates[category] = tmpl
+	}
+	return nil
+}
+
+// Template for a category, falling back to the global template; nil means the built-in format
+func explanationTemplateFor(category string) *template.Template {
+	if tmpl, ok := explanationTemplates[category]; ok {
+		return tmpl
+	}
+	return explanationTemplates[""]
+}
+
+// Build the template data for a cached word, applying the output filters
+func explanationData(word string, category string, cachedData WordCache) ExplanationData {
+	data := ExplanationData{
+		Word:     capitalizePhrase(word),
+		Category: category,
+	}
+	if config.IncludePhonetic {
+		data.Phonetic = cachedData.Phonetic
+		data.Phonetics = entryTranscriptions(cachedData)
+	}
+	if config.IncludeOrigin {
+		data.Origin = cachedData.Origin
+	}
+	if config.IncludeAudio {
+		data.AudioURL = cachedData.AudioURL
+	}
+
+	for _, def := range definitionsForCategory(cachedData.Definitions, category) {
+		example := exampleOf(def)
+		if config.FilterNoExample && example == "" {
+			continue
+		}
+		if definitionLimitReached(len(data.Definitions)) {
+			break
+		}
+		entry := ExplanationDefinition{
+			Number:       len(data.Definitions) + 1,
+			PartOfSpeech: def.PartOfSpeech,
+			Definition:   def.Definition,
+			Example:      example,
+		}
+		if config.IncludeSynonyms {
+			entry.Synonyms = def.Synonyms
+		}
+		if config.IncludeAntonyms {
+			entry.Antonyms = def.Antonyms
+		}
+		data.Definitions = append(data.Definitions, entry)
+	}
+	return data
+}
+
+// Explanation for a word written under the given category. The default text from
+// fetchWordDetails is used unless a template is configured for the category.
+func formatExplanation(word string, category string, defaultText string) string {
+	tmpl := explanationTemplateFor(category)
+	if tmpl == nil {
+		return defaultText
+	}
+
+	cachedData, exists := wordCache[lowerWord(word)]
+	if !exists {
+		return defaultText
+	}
+
+	var output strings.Builder
+	if err := tmpl.Execute(&output, explanationData(word, category, cachedData)); err != nil {
+		return defaultText
+	}
+	text := output.String()
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return text
+}
@@ -0,0 +1,55 @@
+package classifier
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+)
+
+// FileTokens is the categorized word contribution of one input file
+type FileTokens struct {
+	CategorizedWords map[string][]string
+	AllWords         map[string]int
+	// Collected even when ProperNounPhrases.txt and SurfaceForms.txt are off so cached
+	// entries stay complete. Nil for entries cached before they were recorded.
+	ProperNounPhrases map[string]int
+	SurfaceForms      map[string]map[string]int
+	// Input sentences per word, recorded only while corpusExamples is set
+	Sentences map[string][]string
+	// Named entities by label, recorded only while generateEntities is set
+	Entities map[string]map[string]int
+}
+
+var tokenCache = make(map[string]FileTokens)
+var tokenCachePath = "token_cache.json"
+
+// Key for a file's tokenization: its content hash plus the settings that change the result
+func tokenCacheKey(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:]) + ":" + config.Morphology + ":" + contractionMode + ":" + config.HyphenatedWords + ":" + localeTag.String() + ":" + stopwordsKey + ":" + categoriesKey + ":" + scriptsKey
+}
+
+func loadTokenCache() {
+	if _, err := os.Stat(tokenCachePath); os.IsNotExist(err) {
+		return
+	}
+
+	data, err := ioutil.ReadFile(tokenCachePath)
+	if err != nil {
+		return
+	}
+
+	if err := json.Unmarshal(data, &tokenCache); err != nil {
+		tokenCache = make(map[string]FileTokens)
+	}
+}
+
+func saveTokenCache() {
+	data, err := json.Marshal(tokenCache)
+	if err != nil {
+		return
+	}
+	ioutil.WriteFile(tokenCachePath, data, 0644)
+}
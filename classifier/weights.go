@@ -0,0 +1,52 @@
+package classifier
+
+import "sort"
+
+// Weight applied to a word's frequency in AllWords ordering. A word listed in
+// several categories takes the highest weight; unlisted categories weigh 1.
+func categoryWeight(word string, wordCategories map[string][]string) float64 {
+	categories := wordCategories[word]
+	if len(categories) == 0 {
+		return 1
+	}
+	weight := 0.0
+	for _, category := range categories {
+		w, ok := config.CategoryWeights[category]
+		if !ok {
+			w = 1
+		}
+		if w > weight {
+			weight = w
+		}
+	}
+	return weight
+}
+
+// Sort words by frequency scaled by their category weight, highest score first
+// and alphabetically among equal scores
+func sortByWeightedFrequency(counts map[string]int, categorizedWords map[string][]string) []string {
+	wordCategories := make(map[string][]string)
+	for category, words := range categorizedWords {
+		seen := make(map[string]bool)
+		for _, word := range words {
+			if !seen[word] {
+				seen[word] = true
+				wordCategories[word] = append(wordCategories[word], category)
+			}
+		}
+	}
+
+	scores := make(map[string]float64)
+	var result []string
+	for word, freq := range counts {
+		scores[word] = float64(freq) * categoryWeight(word, wordCategories)
+		result = append(result, word)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if scores[result[i]] != scores[result[j]] {
+			return scores[result[i]] > scores[result[j]]
+		}
+		return result[i] < result[j]
+	})
+	return result
+}
@@ -0,0 +1,72 @@
+package classifier
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// WordCloudEntry is a single word-weight pair in the JSON word cloud export
+type WordCloudEntry struct {
+	Text  string  `json:"text"`
+	Value float64 `json:"value"`
+}
+
+// File extension for the configured word cloud format
+func wordCloudExtension() string {
+	if config.WordCloudFormat == "json" {
+		return ".json"
+	}
+	return ".txt"
+}
+
+// Build the word cloud entries from a frequency map, most frequent first
+func buildWordCloud(counts map[string]int) []WordCloudEntry {
+	sortedWords := sortByFrequency(counts)
+	if config.WordCloudTopN > 0 && len(sortedWords) > config.WordCloudTopN {
+		sortedWords = sortedWords[:config.WordCloudTopN]
+	}
+
+	maxFreq := 0
+	for _, word := range sortedWords {
+		if counts[word] > maxFreq {
+			maxFreq = counts[word]
+		}
+	}
+
+	entries := []WordCloudEntry{}
+	for _, word := range sortedWords {
+		weight := float64(counts[word])
+		if config.WordCloudNormalize && maxFreq > 0 {
+			weight = weight / float64(maxFreq)
+		}
+		entries = append(entries, WordCloudEntry{Text: word, Value: weight})
+	}
+	return entries
+}
+
+// Write a word cloud file for the given frequency map
+func writeWordCloud(path string, counts map[string]int) error {
+	entries := buildWordCloud(counts)
+
+	if config.WordCloudFormat == "json" {
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		return writeOutputFile(path, data)
+	}
+
+	file, err := createOutputFile(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for _, entry := range entries {
+		writer.WriteString(fmt.Sprintf("%s:%s\n", entry.Text, strconv.FormatFloat(entry.Value, 'f', -1, 64)))
+	}
+	return writer.Flush()
+}
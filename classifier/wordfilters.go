@@ -0,0 +1,65 @@
+package classifier
+
+// Words of includeWords and includeWordsFile, and of excludeWords and
+// excludeWordsFile, lowercased
+var includeWordList, excludeWordList []string
+
+// Read the include and exclude lists so a missing file is reported at startup
+func loadWordFilters() error {
+	var err error
+	if includeWordList, err = loadWordFilter(config.IncludeWords, config.IncludeWordsFile); err != nil {
+		return err
+	}
+	excludeWordList, err = loadWordFilter(config.ExcludeWords, config.ExcludeWordsFile)
+	return err
+}
+
+// Words listed inline plus those of a word list file
+func loadWordFilter(inline []string, path string) ([]string, error) {
+	var words []string
+	for _, word := range inline {
+		words = append(words, lowerWord(word))
+	}
+	if path != "" {
+		fileWords, err := loadWordList(path)
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, fileWords...)
+	}
+	return deduplicateStrings(words), nil
+}
+
+// Set of the listed words as written and as the morphology setting transforms
+// them, so "running" also matches the lemma "run". Needs the prose models.
+func wordFilterSet(words []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range words {
+		set[word] = true
+		set[morphologyTransform(word, tagWord(word))] = true
+	}
+	return set
+}
+
+// Remove the excluded words from every category
+func removeExcludedWords(categorizedWords map[string][]string, allWords map[string]int, excluded map[string]bool) (map[string][]string, map[string]int) {
+	keep := make(map[string]bool)
+	for word := range allWords {
+		if !excluded[word] {
+			keep[word] = true
+		}
+	}
+	return keepWords(categorizedWords, allWords, keep)
+}
+
+// Included words found in the corpus, which the length, frequency, rank and
+// per-category limits keep like forced words
+func includedWordsIn(allWords map[string]int, included map[string]bool) []string {
+	var words []string
+	for word := range allWords {
+		if included[word] {
+			words = append(words, word)
+		}
+	}
+	return words
+}
@@ -0,0 +1,51 @@
+package classifier
+
+import (
+	"bufio"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Note that every word of a processed file occurred in it, keyed by the file's
+// path relative to the input directory
+func addWordSources(sources map[string]map[string]bool, inputDir string, inputFile string, fileWords map[string]int) {
+	name := inputFile
+	if relPath, err := filepath.Rel(inputDir, inputFile); err == nil {
+		name = filepath.ToSlash(relPath)
+	}
+	for word := range fileWords {
+		if sources[word] == nil {
+			sources[word] = make(map[string]bool)
+		}
+		sources[word][name] = true
+	}
+}
+
+// Write each word, most frequent first, with the input files it came from.
+// Returns the number of words listed.
+func writeWordSources(path string, frequencies map[string]int, sources map[string]map[string]bool) (int, error) {
+	file, err := createOutputFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	listed := 0
+	for _, word := range sortByFrequency(frequencies) {
+		files, ok := sources[word]
+		if !ok {
+			continue
+		}
+		var names []string
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		writer.WriteString(fmt.Sprintf("%s: %s\n", capitalizePhrase(word), strings.Join(names, ", ")))
+		listed++
+	}
+	return listed, writer.Flush()
+}
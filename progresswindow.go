@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Window showing the progress of a run started from the GUI folder picker, for
+// users without a terminal
+type progressWindow struct {
+	window fyne.Window
+	stage  *widget.Label
+	item   *widget.Label
+	bar    *widget.ProgressBar
+	button *widget.Button
+	closed chan struct{}
+	done   atomic.Bool // The run has finished, so the button closes the window
+}
+
+// Replace the picker's content with a progress bar, the current word and a
+// cancel button. Cancelling, or closing the window, calls cancel so the run
+// stops after the current word.
+func showProgressWindow(w fyne.Window, inputDir string, cancel func()) *progressWindow {
+	p := &progressWindow{
+		window: w,
+		stage:  widget.NewLabel("Starting..."),
+		item:   widget.NewLabel(""),
+		bar:    widget.NewProgressBar(),
+		closed: make(chan struct{}),
+	}
+	p.button = widget.NewButton("Cancel", func() { p.cancelOrClose(cancel) })
+	w.SetCloseIntercept(func() { p.cancelOrClose(cancel) })
+
+	w.SetTitle(fmt.Sprintf("Classifying %s", inputDir))
+	w.SetContent(container.NewVBox(p.stage, p.item, p.bar, p.button))
+	w.Resize(fyne.NewSize(500, 150))
+	return p
+}
+
+// Show the progress reported by the classifier
+func (p *progressWindow) update(stage string, item string, current, total int) {
+	p.stage.SetText(fmt.Sprintf("%s (%d of %d)", stage, current, total))
+	p.item.SetText(item)
+	if total > 0 {
+		p.bar.SetValue(float64(current) / float64(total))
+	}
+}
+
+// Stop the run while it is going, or close the window once it has finished
+func (p *progressWindow) cancelOrClose(cancel func()) {
+	if !p.done.Load() {
+		p.stage.SetText("Cancelling after the current word...")
+		p.button.Disable()
+		cancel()
+		return
+	}
+	p.window.Close()
+	close(p.closed)
+}
+
+// Show the outcome of the run and wait for the user to close the window
+func (p *progressWindow) finish(message string) {
+	p.done.Store(true)
+	p.stage.SetText(message)
+	p.item.SetText("")
+	p.button.SetText("Close")
+	p.button.Enable()
+	<-p.closed
+}